@@ -0,0 +1,222 @@
+package analysis
+
+import (
+	"monkey/ast"
+	"monkey/token"
+)
+
+type Kind string
+
+const (
+	LET       Kind = "let"
+	CONST     Kind = "const"
+	PARAMETER Kind = "parameter"
+	BINDING   Kind = "binding"
+)
+
+type Symbol struct {
+	Name       string
+	Kind       Kind
+	Identifier *ast.Identifier
+	Statement  *ast.LetStatement
+	References []*ast.Identifier
+}
+
+func (s *Symbol) Function() *ast.FunctionLiteral {
+	if s.Statement == nil || s.Statement.Pattern != nil {
+		return nil
+	}
+
+	function, _ := s.Statement.Value.(*ast.FunctionLiteral)
+	return function
+}
+
+type Result struct {
+	Symbols     []*Symbol
+	Identifiers map[*ast.Identifier]*Symbol
+	Unresolved  []*ast.Identifier
+}
+
+func (r *Result) Lookup(identifier *ast.Identifier) *Symbol {
+	return r.Identifiers[identifier]
+}
+
+func (r *Result) IdentifierAt(offset int) *ast.Identifier {
+	for identifier := range r.Identifiers {
+		if contains(identifier, offset) {
+			return identifier
+		}
+	}
+	for _, identifier := range r.Unresolved {
+		if contains(identifier, offset) {
+			return identifier
+		}
+	}
+	return nil
+}
+
+func contains(identifier *ast.Identifier, offset int) bool {
+	start := identifier.Token.Position.Offset
+	return start <= offset && offset <= start+len(identifier.Value)
+}
+
+type scope struct {
+	symbols map[string]*Symbol
+	outer   *scope
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{symbols: make(map[string]*Symbol), outer: outer}
+}
+
+func (s *scope) lookup(name string) *Symbol {
+	for current := s; current != nil; current = current.outer {
+		if symbol, ok := current.symbols[name]; ok {
+			return symbol
+		}
+	}
+	return nil
+}
+
+type analyzer struct {
+	result   *Result
+	deferred []func()
+}
+
+func Analyze(program *ast.Program) *Result {
+	a := &analyzer{result: &Result{Identifiers: make(map[*ast.Identifier]*Symbol)}}
+
+	global := newScope(nil)
+	for _, statement := range program.Statements {
+		a.statement(statement, global)
+	}
+
+	for len(a.deferred) > 0 {
+		next := a.deferred[0]
+		a.deferred = a.deferred[1:]
+		next()
+	}
+
+	return a.result
+}
+
+func (a *analyzer) define(s *scope, identifier *ast.Identifier, kind Kind, statement *ast.LetStatement) {
+	symbol := &Symbol{Name: identifier.Value, Kind: kind, Identifier: identifier, Statement: statement}
+
+	s.symbols[identifier.Value] = symbol
+	a.result.Symbols = append(a.result.Symbols, symbol)
+	a.result.Identifiers[identifier] = symbol
+}
+
+func (a *analyzer) resolve(s *scope, identifier *ast.Identifier) {
+	symbol := s.lookup(identifier.Value)
+	if symbol == nil {
+		a.result.Unresolved = append(a.result.Unresolved, identifier)
+		return
+	}
+
+	symbol.References = append(symbol.References, identifier)
+	a.result.Identifiers[identifier] = symbol
+}
+
+func (a *analyzer) statement(statement ast.Statement, s *scope) {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		a.expression(statement.Value, s)
+
+		kind := LET
+		if statement.Token.Type == token.CONST {
+			kind = CONST
+		}
+
+		if statement.Pattern != nil {
+			for _, name := range ast.PatternNames(statement.Pattern) {
+				a.define(s, name, kind, statement)
+			}
+		} else if statement.Name != nil {
+			a.define(s, statement.Name, kind, statement)
+		}
+	case *ast.ReturnStatement:
+		a.expression(statement.ReturnValue, s)
+	case *ast.ExpressionStatement:
+		a.expression(statement.Expression, s)
+	case *ast.BlockStatement:
+		a.block(statement, s)
+	}
+}
+
+func (a *analyzer) block(block *ast.BlockStatement, s *scope) {
+	if block == nil {
+		return
+	}
+
+	for _, statement := range block.Statements {
+		a.statement(statement, s)
+	}
+}
+
+func (a *analyzer) expression(expression ast.Expression, s *scope) {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		a.resolve(s, expression)
+	case *ast.PrefixExpression:
+		a.expression(expression.Right, s)
+	case *ast.InfixExpression:
+		a.expression(expression.Left, s)
+		a.expression(expression.Right, s)
+	case *ast.IfExpression:
+		a.expression(expression.Condition, s)
+		a.block(expression.Consequence, s)
+		a.block(expression.Alternative, s)
+	case *ast.FunctionLiteral:
+		function := newScope(s)
+		for _, parameter := range expression.Parameters {
+			a.define(function, parameter, PARAMETER, nil)
+		}
+		a.deferred = append(a.deferred, func() {
+			a.block(expression.Body, function)
+		})
+	case *ast.CallExpression:
+		a.expression(expression.Function, s)
+		for _, argument := range expression.Arguments {
+			a.expression(argument, s)
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range expression.Elements {
+			a.expression(element, s)
+		}
+	case *ast.IndexExpression:
+		a.expression(expression.Left, s)
+		a.expression(expression.Index, s)
+	case *ast.SliceExpression:
+		a.expression(expression.Left, s)
+		a.expression(expression.Start, s)
+		a.expression(expression.End, s)
+	case *ast.HashLiteral:
+		for _, pair := range expression.Pairs {
+			a.expression(pair.Key, s)
+			a.expression(pair.Value, s)
+		}
+	case *ast.MatchExpression:
+		a.expression(expression.Subject, s)
+		for _, arm := range expression.Arms {
+			a.matchArm(arm, s)
+		}
+	}
+}
+
+func (a *analyzer) matchArm(arm *ast.MatchArm, s *scope) {
+	enclosed := newScope(s)
+
+	for _, name := range ast.PatternNames(arm.Pattern) {
+		if symbol, ok := enclosed.symbols[name.Value]; ok {
+			symbol.References = append(symbol.References, name)
+			a.result.Identifiers[name] = symbol
+			continue
+		}
+		a.define(enclosed, name, BINDING, nil)
+	}
+
+	a.expression(arm.Guard, enclosed)
+	a.block(arm.Body, enclosed)
+}
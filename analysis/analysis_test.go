@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"monkey/lexer"
+	"monkey/parser"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	input := `let add = fn(x, y) { x + y };
+const limit = 10;
+let [first, ...rest] = [1, 2, 3];
+let total = add(first, limit);
+let loop = fn(n) { if (n > 0) { loop(n - 1) } else { later } };
+let later = match (rest) { [a, b] if a < b => a + b, [a] | [a, _] => a, _ => len(rest) };
+unknown;`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	result := Analyze(program)
+
+	tests := []struct {
+		name       string
+		kind       Kind
+		references int
+		function   bool
+	}{
+		{"add", LET, 1, true},
+		{"limit", CONST, 1, false},
+		{"first", LET, 1, false},
+		{"rest", LET, 2, false},
+		{"total", LET, 0, false},
+		{"loop", LET, 1, true},
+		{"later", LET, 1, false},
+		{"x", PARAMETER, 1, false},
+		{"y", PARAMETER, 1, false},
+		{"n", PARAMETER, 2, false},
+	}
+
+	symbols := make(map[string]*Symbol)
+	for _, symbol := range result.Symbols {
+		if _, ok := symbols[symbol.Name]; !ok {
+			symbols[symbol.Name] = symbol
+		}
+	}
+
+	for _, tt := range tests {
+		symbol, ok := symbols[tt.name]
+		if !ok {
+			t.Errorf("symbol %s not found", tt.name)
+			continue
+		}
+		if symbol.Kind != tt.kind {
+			t.Errorf("kind of %s expected : %s, but was actual : %s", tt.name, tt.kind, symbol.Kind)
+		}
+		if len(symbol.References) != tt.references {
+			t.Errorf("references of %s expected : %d, but was actual : %d", tt.name, tt.references, len(symbol.References))
+		}
+		if (symbol.Function() != nil) != tt.function {
+			t.Errorf("function of %s expected : %t, but was actual : %t", tt.name, tt.function, symbol.Function() != nil)
+		}
+	}
+
+	var bindings []string
+	for _, symbol := range result.Symbols {
+		if symbol.Kind == BINDING {
+			bindings = append(bindings, symbol.Name)
+		}
+	}
+	if len(bindings) != 3 {
+		t.Errorf("match bindings expected : [a b a], but was actual : %v", bindings)
+	}
+
+	var unresolved []string
+	for _, identifier := range result.Unresolved {
+		unresolved = append(unresolved, identifier.Value)
+	}
+	if len(unresolved) != 2 || unresolved[0] != "len" || unresolved[1] != "unknown" {
+		t.Errorf("unresolved expected : [len unknown], but was actual : %v", unresolved)
+	}
+}
+
+func TestIdentifierAt(t *testing.T) {
+	input := "let value = 1;\nvalue + value"
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	result := Analyze(program)
+
+	tests := []struct {
+		offset   int
+		expected int
+	}{
+		{4, 4},
+		{9, 4},
+		{15, 15},
+		{20, 15},
+		{23, 23},
+		{0, -1},
+		{13, -1},
+	}
+
+	for _, tt := range tests {
+		identifier := result.IdentifierAt(tt.offset)
+
+		actual := -1
+		if identifier != nil {
+			actual = identifier.Token.Position.Offset
+			if result.Lookup(identifier).Identifier.Token.Position.Offset != 4 {
+				t.Errorf("identifier at %d does not resolve to value", tt.offset)
+			}
+		}
+		if actual != tt.expected {
+			t.Errorf("identifier at %d expected : %d, but was actual : %d", tt.offset, tt.expected, actual)
+		}
+	}
+}
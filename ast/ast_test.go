@@ -1,7 +1,10 @@
 package ast
 
 import (
+	"bytes"
+	"encoding/json"
 	"monkey/token"
+	"strings"
 	"testing"
 )
 
@@ -27,3 +30,182 @@ func TestString(t *testing.T) {
 		t.Errorf("expected : [let myVar = anotherVar;], but was actual : [%s]", s)
 	}
 }
+
+func TestInspect(t *testing.T) {
+	identifier := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.ID, Literal: name}, Value: name}
+	}
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  identifier("f"),
+				Value: &FunctionLiteral{
+					Parameters: []*Identifier{identifier("x")},
+					Body: &BlockStatement{
+						Statements: []Statement{
+							&ExpressionStatement{
+								Expression: &InfixExpression{Left: identifier("x"), Operator: "+", Right: &NumberLiteral{Value: 1}},
+							},
+						},
+					},
+				},
+			},
+			&ExpressionStatement{
+				Expression: &CallExpression{
+					Function:  identifier("f"),
+					Arguments: []Expression{&MatchExpression{Subject: identifier("y"), Arms: []*MatchArm{{Pattern: identifier("_")}}}},
+				},
+			},
+		},
+	}
+
+	var names []string
+	nodes := 0
+	Inspect(program, func(node Node) bool {
+		nodes++
+		if identifier, ok := node.(*Identifier); ok {
+			names = append(names, identifier.Value)
+		}
+		return true
+	})
+
+	if strings.Join(names, " ") != "f x x f y _" {
+		t.Errorf("identifiers expected : [f x x f y _], but was actual : %v", names)
+	}
+	if nodes != 17 {
+		t.Errorf("nodes expected : 17, but was actual : %d", nodes)
+	}
+
+	skipped := 0
+	Inspect(program, func(node Node) bool {
+		skipped++
+		_, ok := node.(*FunctionLiteral)
+		return !ok
+	})
+	if skipped != 11 {
+		t.Errorf("nodes outside function bodies expected : 11, but was actual : %d", skipped)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	node := &InfixExpression{
+		Token:    token.At(token.PLUS, "+", token.Position{Offset: 2, Line: 1, Column: 3}),
+		Operator: "+",
+		Left:     &NumberLiteral{Token: token.At(token.NUMBER, "1", token.Position{Offset: 0, Line: 1, Column: 1}), Value: 1},
+		Right:    &Identifier{Token: token.At(token.ID, "x", token.Position{Offset: 4, Line: 1, Column: 5}), Value: "x"},
+	}
+
+	encoded, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("marshal returned error : %s", err)
+	}
+
+	expected := `{"kind":"InfixExpression",` +
+		`"token":{"type":"+","literal":"+","position":{"offset":2,"line":1,"column":3}},` +
+		`"left":{"kind":"NumberLiteral","token":{"type":"NUMBER","literal":"1","position":{"offset":0,"line":1,"column":1}},"value":1},` +
+		`"operator":"+",` +
+		`"right":{"kind":"Identifier","token":{"type":"ID","literal":"x","position":{"offset":4,"line":1,"column":5}},"value":"x"}}`
+	if string(encoded) != expected {
+		t.Errorf("encoded expected :\n%s\nbut was actual :\n%s", expected, encoded)
+	}
+
+	decoded, err := UnmarshalNode(encoded)
+	if err != nil {
+		t.Fatalf("unmarshal returned error : %s", err)
+	}
+	if decoded.String() != "(1 + x)" {
+		t.Errorf("decoded expected : (1 + x), but was actual : %s", decoded.String())
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"kind":"Unknown"}`, "unknown node kind : Unknown"},
+		{`{"statements":[]}`, `node kind missing : {"statements":[]}`},
+		{`{"kind":"Program","statements":[{"kind":"Identifier","value":"x"}]}`, `statement expected : {"kind":"Identifier","value":"x"}`},
+		{`{"kind":"ExpressionStatement"}`, "program expected : *ast.ExpressionStatement"},
+		{`{"kind":"Program","statements":[{"kind":"ExpressionStatement","expression":{"kind":"BlockStatement"}}]}`, `expression expected : {"kind":"BlockStatement"}`},
+	}
+
+	for _, tt := range tests {
+		var program Program
+		err := json.Unmarshal([]byte(tt.input), &program)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("error of %s expected : %s, but was actual : %v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func testProgram() *Program {
+	position := func(column int) token.Position {
+		return token.Position{Offset: column - 1, Line: 1, Column: column}
+	}
+
+	return &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.At(token.CONST, "const", position(1)),
+				Name:  &Identifier{Token: token.At(token.ID, "x", position(7)), Value: "x"},
+				Value: &InfixExpression{
+					Token:    token.At(token.PLUS, "+", position(13)),
+					Operator: "+",
+					Left:     &NumberLiteral{Token: token.At(token.NUMBER, "1", position(11)), Value: 1},
+					Right: &CallExpression{
+						Token:     token.At(token.LPAREN, "(", position(16)),
+						Function:  &Identifier{Token: token.At(token.ID, "f", position(15)), Value: "f"},
+						Arguments: []Expression{&StringLiteral{Token: token.At(token.STRING, "a", position(17)), Value: "a"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFprint(t *testing.T) {
+	var out bytes.Buffer
+	if err := Fprint(&out, testProgram()); err != nil {
+		t.Fatalf("Fprint returned error : %s", err)
+	}
+
+	expected := `Program
+  statements[0]: LetStatement const @1:1
+    name: Identifier "x" @1:7
+    value: InfixExpression "+" @1:13
+      left: NumberLiteral 1 @1:11
+      right: CallExpression @1:16
+        function: Identifier "f" @1:15
+        arguments[0]: StringLiteral "a" @1:17
+`
+	if out.String() != expected {
+		t.Errorf("tree expected :\n%s\nbut was actual :\n%s", expected, out.String())
+	}
+}
+
+func TestFprintDot(t *testing.T) {
+	var out bytes.Buffer
+	if err := FprintDot(&out, testProgram().Statements[0].(*LetStatement).Value); err != nil {
+		t.Fatalf("FprintDot returned error : %s", err)
+	}
+
+	expected := `digraph ast {
+  node [shape=box, fontname="monospace"];
+  n0 [label="InfixExpression \"+\"\n1:13"];
+  n1 [label="NumberLiteral 1\n1:11"];
+  n0 -> n1 [label="left"];
+  n2 [label="CallExpression\n1:16"];
+  n3 [label="Identifier \"f\"\n1:15"];
+  n2 -> n3 [label="function"];
+  n4 [label="StringLiteral \"a\"\n1:17"];
+  n2 -> n4 [label="arguments[0]"];
+  n0 -> n2 [label="right"];
+}
+`
+	if out.String() != expected {
+		t.Errorf("dot expected :\n%s\nbut was actual :\n%s", expected, out.String())
+	}
+}
@@ -12,8 +12,14 @@ type Expression interface {
 }
 
 type Identifier struct {
-	Token token.Token
-	Value string
+	Token   token.Token
+	Value   string
+	Binding *Binding
+}
+
+type Binding struct {
+	Depth int
+	Slot  int
 }
 
 func (i *Identifier) expressionNode() {}
@@ -137,6 +143,7 @@ type FunctionLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
 	Body       *BlockStatement
+	Slots      int
 }
 
 func (f *FunctionLiteral) TokenLiteral() string {
@@ -166,6 +173,7 @@ type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	Tail      bool
 }
 
 func (c *CallExpression) TokenLiteral() string {
@@ -255,3 +263,120 @@ func (i *IndexExpression) String() string {
 }
 
 func (i *IndexExpression) expressionNode() {}
+
+type SliceExpression struct {
+	Token token.Token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+func (s *SliceExpression) TokenLiteral() string {
+	return s.Token.Literal
+}
+
+func (s *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(s.Left.String())
+	out.WriteString("[")
+	if s.Start != nil {
+		out.WriteString(s.Start.String())
+	}
+	out.WriteString(":")
+	if s.End != nil {
+		out.WriteString(s.End.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+func (s *SliceExpression) expressionNode() {}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+type HashLiteral struct {
+	Token token.Token
+	Pairs []*HashPair
+}
+
+func (h *HashLiteral) TokenLiteral() string {
+	return h.Token.Literal
+}
+
+func (h *HashLiteral) String() string {
+	var out bytes.Buffer
+	var pairs []string
+
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+func (h *HashLiteral) expressionNode() {}
+
+type MatchArm struct {
+	Token   token.Token
+	Pattern Pattern
+	Guard   Expression
+	Body    *BlockStatement
+	Slots   int
+}
+
+func (m *MatchArm) TokenLiteral() string {
+	return m.Token.Literal
+}
+
+func (m *MatchArm) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(m.Pattern.String())
+	if m.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(m.Guard.String())
+	}
+	out.WriteString(" => ")
+	out.WriteString(m.Body.String())
+
+	return out.String()
+}
+
+type MatchExpression struct {
+	Token   token.Token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+func (m *MatchExpression) TokenLiteral() string {
+	return m.Token.Literal
+}
+
+func (m *MatchExpression) String() string {
+	var out bytes.Buffer
+	var arms []string
+
+	for _, arm := range m.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	out.WriteString("match (")
+	out.WriteString(m.Subject.String())
+	out.WriteString(") { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+func (m *MatchExpression) expressionNode() {}
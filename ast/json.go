@@ -0,0 +1,585 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"monkey/token"
+	"sort"
+	"strconv"
+)
+
+type jsonObject map[string]interface{}
+
+func writeJSON(out *bytes.Buffer, value interface{}) error {
+	switch value := value.(type) {
+	case jsonObject:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			if key != "kind" && key != "token" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range []string{"token", "kind"} {
+			if _, ok := value[key]; ok {
+				keys = append([]string{key}, keys...)
+			}
+		}
+
+		out.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			out.WriteString(strconv.Quote(key) + ":")
+			if err := writeJSON(out, value[key]); err != nil {
+				return err
+			}
+		}
+		out.WriteString("}")
+	case []interface{}:
+		out.WriteString("[")
+		for i, element := range value {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			if err := writeJSON(out, element); err != nil {
+				return err
+			}
+		}
+		out.WriteString("]")
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		out.Write(encoded)
+	}
+	return nil
+}
+
+func encode(node Node) interface{} {
+	switch node := node.(type) {
+	case *Program:
+		return jsonObject{"kind": "Program", "statements": encodeStatements(node.Statements)}
+	case *LetStatement:
+		encoded := jsonObject{"kind": "LetStatement", "token": node.Token, "value": encode(node.Value)}
+		if node.Pattern != nil {
+			encoded["pattern"] = encode(node.Pattern)
+		} else if node.Name != nil {
+			encoded["name"] = encode(node.Name)
+		}
+		return encoded
+	case *ReturnStatement:
+		return jsonObject{"kind": "ReturnStatement", "token": node.Token, "returnValue": encode(node.ReturnValue)}
+	case *ExpressionStatement:
+		return jsonObject{"kind": "ExpressionStatement", "token": node.Token, "expression": encode(node.Expression)}
+	case *BlockStatement:
+		if node == nil {
+			return nil
+		}
+		return jsonObject{"kind": "BlockStatement", "token": node.Token, "statements": encodeStatements(node.Statements)}
+	case *Identifier:
+		if node == nil {
+			return nil
+		}
+		return jsonObject{"kind": "Identifier", "token": node.Token, "value": node.Value}
+	case *NumberLiteral:
+		return jsonObject{"kind": "NumberLiteral", "token": node.Token, "value": node.Value}
+	case *StringLiteral:
+		return jsonObject{"kind": "StringLiteral", "token": node.Token, "value": node.Value}
+	case *Boolean:
+		return jsonObject{"kind": "Boolean", "token": node.Token, "value": node.Value}
+	case *PrefixExpression:
+		return jsonObject{"kind": "PrefixExpression", "token": node.Token, "operator": node.Operator, "right": encode(node.Right)}
+	case *InfixExpression:
+		return jsonObject{
+			"kind":     "InfixExpression",
+			"token":    node.Token,
+			"operator": node.Operator,
+			"left":     encode(node.Left),
+			"right":    encode(node.Right),
+		}
+	case *IfExpression:
+		return jsonObject{
+			"kind":        "IfExpression",
+			"token":       node.Token,
+			"condition":   encode(node.Condition),
+			"consequence": encode(node.Consequence),
+			"alternative": encode(node.Alternative),
+		}
+	case *FunctionLiteral:
+		parameters := make([]interface{}, len(node.Parameters))
+		for i, parameter := range node.Parameters {
+			parameters[i] = encode(parameter)
+		}
+		return jsonObject{"kind": "FunctionLiteral", "token": node.Token, "parameters": parameters, "body": encode(node.Body)}
+	case *CallExpression:
+		return jsonObject{
+			"kind":      "CallExpression",
+			"token":     node.Token,
+			"function":  encode(node.Function),
+			"arguments": encodeExpressions(node.Arguments),
+		}
+	case *ArrayLiteral:
+		return jsonObject{"kind": "ArrayLiteral", "token": node.Token, "elements": encodeExpressions(node.Elements)}
+	case *IndexExpression:
+		return jsonObject{"kind": "IndexExpression", "token": node.Token, "left": encode(node.Left), "index": encode(node.Index)}
+	case *SliceExpression:
+		return jsonObject{
+			"kind":  "SliceExpression",
+			"token": node.Token,
+			"left":  encode(node.Left),
+			"start": encode(node.Start),
+			"end":   encode(node.End),
+		}
+	case *HashLiteral:
+		pairs := make([]interface{}, len(node.Pairs))
+		for i, pair := range node.Pairs {
+			pairs[i] = jsonObject{"key": encode(pair.Key), "value": encode(pair.Value)}
+		}
+		return jsonObject{"kind": "HashLiteral", "token": node.Token, "pairs": pairs}
+	case *MatchExpression:
+		arms := make([]interface{}, len(node.Arms))
+		for i, arm := range node.Arms {
+			arms[i] = encode(arm)
+		}
+		return jsonObject{"kind": "MatchExpression", "token": node.Token, "subject": encode(node.Subject), "arms": arms}
+	case *MatchArm:
+		return jsonObject{
+			"kind":    "MatchArm",
+			"token":   node.Token,
+			"pattern": encode(node.Pattern),
+			"guard":   encode(node.Guard),
+			"body":    encode(node.Body),
+		}
+	case *LiteralPattern:
+		return jsonObject{"kind": "LiteralPattern", "token": node.Token, "value": encode(node.Value)}
+	case *ArrayPattern:
+		elements := make([]interface{}, len(node.Elements))
+		for i, element := range node.Elements {
+			elements[i] = encode(element)
+		}
+		return jsonObject{"kind": "ArrayPattern", "token": node.Token, "elements": elements, "rest": encode(node.Rest)}
+	case *HashPattern:
+		pairs := make([]interface{}, len(node.Pairs))
+		for i, pair := range node.Pairs {
+			pairs[i] = jsonObject{"key": encode(pair.Key), "value": encode(pair.Value)}
+		}
+		return jsonObject{"kind": "HashPattern", "token": node.Token, "pairs": pairs}
+	case *AlternativePattern:
+		alternatives := make([]interface{}, len(node.Alternatives))
+		for i, alternative := range node.Alternatives {
+			alternatives[i] = encode(alternative)
+		}
+		return jsonObject{"kind": "AlternativePattern", "token": node.Token, "alternatives": alternatives}
+	default:
+		return nil
+	}
+}
+
+func encodeStatements(statements []Statement) []interface{} {
+	encoded := make([]interface{}, len(statements))
+	for i, statement := range statements {
+		encoded[i] = encode(statement)
+	}
+	return encoded
+}
+
+func encodeExpressions(expressions []Expression) []interface{} {
+	encoded := make([]interface{}, len(expressions))
+	for i, expression := range expressions {
+		encoded[i] = encode(expression)
+	}
+	return encoded
+}
+
+func marshal(node Node) ([]byte, error) {
+	var out bytes.Buffer
+	if err := writeJSON(&out, encode(node)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (p *Program) MarshalJSON() ([]byte, error) {
+	return marshal(p)
+}
+
+func (l *LetStatement) MarshalJSON() ([]byte, error) {
+	return marshal(l)
+}
+
+func (r *ReturnStatement) MarshalJSON() ([]byte, error) {
+	return marshal(r)
+}
+
+func (e *ExpressionStatement) MarshalJSON() ([]byte, error) {
+	return marshal(e)
+}
+
+func (b *BlockStatement) MarshalJSON() ([]byte, error) {
+	return marshal(b)
+}
+
+func (i *Identifier) MarshalJSON() ([]byte, error) {
+	return marshal(i)
+}
+
+func (n *NumberLiteral) MarshalJSON() ([]byte, error) {
+	return marshal(n)
+}
+
+func (s *StringLiteral) MarshalJSON() ([]byte, error) {
+	return marshal(s)
+}
+
+func (b *Boolean) MarshalJSON() ([]byte, error) {
+	return marshal(b)
+}
+
+func (p *PrefixExpression) MarshalJSON() ([]byte, error) {
+	return marshal(p)
+}
+
+func (i *InfixExpression) MarshalJSON() ([]byte, error) {
+	return marshal(i)
+}
+
+func (i *IfExpression) MarshalJSON() ([]byte, error) {
+	return marshal(i)
+}
+
+func (f *FunctionLiteral) MarshalJSON() ([]byte, error) {
+	return marshal(f)
+}
+
+func (c *CallExpression) MarshalJSON() ([]byte, error) {
+	return marshal(c)
+}
+
+func (a *ArrayLiteral) MarshalJSON() ([]byte, error) {
+	return marshal(a)
+}
+
+func (i *IndexExpression) MarshalJSON() ([]byte, error) {
+	return marshal(i)
+}
+
+func (s *SliceExpression) MarshalJSON() ([]byte, error) {
+	return marshal(s)
+}
+
+func (h *HashLiteral) MarshalJSON() ([]byte, error) {
+	return marshal(h)
+}
+
+func (m *MatchExpression) MarshalJSON() ([]byte, error) {
+	return marshal(m)
+}
+
+func (m *MatchArm) MarshalJSON() ([]byte, error) {
+	return marshal(m)
+}
+
+func (l *LiteralPattern) MarshalJSON() ([]byte, error) {
+	return marshal(l)
+}
+
+func (a *ArrayPattern) MarshalJSON() ([]byte, error) {
+	return marshal(a)
+}
+
+func (h *HashPattern) MarshalJSON() ([]byte, error) {
+	return marshal(h)
+}
+
+func (a *AlternativePattern) MarshalJSON() ([]byte, error) {
+	return marshal(a)
+}
+
+func (p *Program) UnmarshalJSON(data []byte) error {
+	node, err := UnmarshalNode(data)
+	if err != nil {
+		return err
+	}
+
+	program, ok := node.(*Program)
+	if !ok {
+		return fmt.Errorf("program expected : %T", node)
+	}
+
+	*p = *program
+	return nil
+}
+
+func UnmarshalNode(data []byte) (Node, error) {
+	return decode(data)
+}
+
+type fields map[string]json.RawMessage
+
+func decode(data json.RawMessage) (Node, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	var kind string
+	if err := json.Unmarshal(f["kind"], &kind); err != nil {
+		return nil, fmt.Errorf("node kind missing : %s", data)
+	}
+
+	var t token.Token
+	if raw, ok := f["token"]; ok {
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, err
+		}
+	}
+
+	d := &decoder{fields: f}
+
+	var node Node
+	switch kind {
+	case "Program":
+		node = &Program{Statements: d.statements("statements")}
+	case "LetStatement":
+		node = &LetStatement{
+			Token:   t,
+			Name:    d.identifier("name"),
+			Pattern: d.pattern("pattern"),
+			Value:   d.expression("value"),
+		}
+	case "ReturnStatement":
+		node = &ReturnStatement{Token: t, ReturnValue: d.expression("returnValue")}
+	case "ExpressionStatement":
+		node = &ExpressionStatement{Token: t, Expression: d.expression("expression")}
+	case "BlockStatement":
+		node = &BlockStatement{Token: t, Statements: d.statements("statements")}
+	case "Identifier":
+		identifier := &Identifier{Token: t}
+		d.value("value", &identifier.Value)
+		node = identifier
+	case "NumberLiteral":
+		number := &NumberLiteral{Token: t}
+		d.value("value", &number.Value)
+		node = number
+	case "StringLiteral":
+		s := &StringLiteral{Token: t}
+		d.value("value", &s.Value)
+		node = s
+	case "Boolean":
+		boolean := &Boolean{Token: t}
+		d.value("value", &boolean.Value)
+		node = boolean
+	case "PrefixExpression":
+		prefix := &PrefixExpression{Token: t, Right: d.expression("right")}
+		d.value("operator", &prefix.Operator)
+		node = prefix
+	case "InfixExpression":
+		infix := &InfixExpression{Token: t, Left: d.expression("left"), Right: d.expression("right")}
+		d.value("operator", &infix.Operator)
+		node = infix
+	case "IfExpression":
+		node = &IfExpression{
+			Token:       t,
+			Condition:   d.expression("condition"),
+			Consequence: d.block("consequence"),
+			Alternative: d.block("alternative"),
+		}
+	case "FunctionLiteral":
+		function := &FunctionLiteral{Token: t, Body: d.block("body")}
+		for _, raw := range d.list("parameters") {
+			function.Parameters = append(function.Parameters, d.identifierFrom(raw))
+		}
+		node = function
+	case "CallExpression":
+		node = &CallExpression{Token: t, Function: d.expression("function"), Arguments: d.expressions("arguments")}
+	case "ArrayLiteral":
+		node = &ArrayLiteral{Token: t, Elements: d.expressions("elements")}
+	case "IndexExpression":
+		node = &IndexExpression{Token: t, Left: d.expression("left"), Index: d.expression("index")}
+	case "SliceExpression":
+		node = &SliceExpression{Token: t, Left: d.expression("left"), Start: d.expression("start"), End: d.expression("end")}
+	case "HashLiteral":
+		hash := &HashLiteral{Token: t, Pairs: []*HashPair{}}
+		for _, raw := range d.list("pairs") {
+			var pair fields
+			if err := json.Unmarshal(raw, &pair); err != nil {
+				return nil, err
+			}
+			pairDecoder := &decoder{fields: pair}
+			hash.Pairs = append(hash.Pairs, &HashPair{Key: pairDecoder.expression("key"), Value: pairDecoder.expression("value")})
+			d.fail(pairDecoder.err)
+		}
+		node = hash
+	case "MatchExpression":
+		match := &MatchExpression{Token: t, Subject: d.expression("subject")}
+		for _, raw := range d.list("arms") {
+			arm, ok := d.node(raw).(*MatchArm)
+			if !ok {
+				d.fail(fmt.Errorf("match arm expected : %s", raw))
+			}
+			match.Arms = append(match.Arms, arm)
+		}
+		node = match
+	case "MatchArm":
+		node = &MatchArm{Token: t, Pattern: d.pattern("pattern"), Guard: d.expression("guard"), Body: d.block("body")}
+	case "LiteralPattern":
+		node = &LiteralPattern{Token: t, Value: d.expression("value")}
+	case "ArrayPattern":
+		pattern := &ArrayPattern{Token: t, Rest: d.identifier("rest")}
+		for _, raw := range d.list("elements") {
+			pattern.Elements = append(pattern.Elements, d.patternFrom(raw))
+		}
+		node = pattern
+	case "HashPattern":
+		pattern := &HashPattern{Token: t}
+		for _, raw := range d.list("pairs") {
+			var pair fields
+			if err := json.Unmarshal(raw, &pair); err != nil {
+				return nil, err
+			}
+			pairDecoder := &decoder{fields: pair}
+			key, ok := pairDecoder.expression("key").(*StringLiteral)
+			if !ok {
+				d.fail(fmt.Errorf("string key expected : %s", pair["key"]))
+			}
+			pattern.Pairs = append(pattern.Pairs, &HashPatternPair{Key: key, Value: pairDecoder.pattern("value")})
+			d.fail(pairDecoder.err)
+		}
+		node = pattern
+	case "AlternativePattern":
+		pattern := &AlternativePattern{Token: t}
+		for _, raw := range d.list("alternatives") {
+			pattern.Alternatives = append(pattern.Alternatives, d.patternFrom(raw))
+		}
+		node = pattern
+	default:
+		return nil, fmt.Errorf("unknown node kind : %s", kind)
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	return node, nil
+}
+
+type decoder struct {
+	fields fields
+	err    error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil && err != nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) value(key string, target interface{}) {
+	if raw, ok := d.fields[key]; ok {
+		d.fail(json.Unmarshal(raw, target))
+	}
+}
+
+func (d *decoder) list(key string) []json.RawMessage {
+	var list []json.RawMessage
+	d.value(key, &list)
+	return list
+}
+
+func (d *decoder) node(raw json.RawMessage) Node {
+	node, err := decode(raw)
+	d.fail(err)
+	return node
+}
+
+func (d *decoder) statements(key string) []Statement {
+	var statements []Statement
+	for _, raw := range d.list(key) {
+		statement, ok := d.node(raw).(Statement)
+		if !ok {
+			d.fail(fmt.Errorf("statement expected : %s", raw))
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+func (d *decoder) expression(key string) Expression {
+	return d.expressionFrom(d.fields[key])
+}
+
+func (d *decoder) expressionFrom(raw json.RawMessage) Expression {
+	node := d.node(raw)
+	if node == nil {
+		return nil
+	}
+
+	expression, ok := node.(Expression)
+	if !ok {
+		d.fail(fmt.Errorf("expression expected : %s", raw))
+	}
+	return expression
+}
+
+func (d *decoder) expressions(key string) []Expression {
+	var expressions []Expression
+	for _, raw := range d.list(key) {
+		expressions = append(expressions, d.expressionFrom(raw))
+	}
+	return expressions
+}
+
+func (d *decoder) block(key string) *BlockStatement {
+	node := d.node(d.fields[key])
+	if node == nil {
+		return nil
+	}
+
+	block, ok := node.(*BlockStatement)
+	if !ok {
+		d.fail(fmt.Errorf("block expected : %s", d.fields[key]))
+	}
+	return block
+}
+
+func (d *decoder) identifier(key string) *Identifier {
+	return d.identifierFrom(d.fields[key])
+}
+
+func (d *decoder) identifierFrom(raw json.RawMessage) *Identifier {
+	node := d.node(raw)
+	if node == nil {
+		return nil
+	}
+
+	identifier, ok := node.(*Identifier)
+	if !ok {
+		d.fail(fmt.Errorf("identifier expected : %s", raw))
+	}
+	return identifier
+}
+
+func (d *decoder) pattern(key string) Pattern {
+	return d.patternFrom(d.fields[key])
+}
+
+func (d *decoder) patternFrom(raw json.RawMessage) Pattern {
+	node := d.node(raw)
+	if node == nil {
+		return nil
+	}
+
+	pattern, ok := node.(Pattern)
+	if !ok {
+		d.fail(fmt.Errorf("pattern expected : %s", raw))
+	}
+	return pattern
+}
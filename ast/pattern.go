@@ -0,0 +1,151 @@
+package ast
+
+import (
+	"bytes"
+	"monkey/token"
+	"strings"
+)
+
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+func (i *Identifier) patternNode() {}
+
+type LiteralPattern struct {
+	Token token.Token
+	Value Expression
+}
+
+func (l *LiteralPattern) TokenLiteral() string {
+	return l.Token.Literal
+}
+
+func (l *LiteralPattern) String() string {
+	if s, ok := l.Value.(*StringLiteral); ok {
+		return `"` + s.Value + `"`
+	}
+	return l.Value.String()
+}
+
+func (l *LiteralPattern) patternNode() {}
+
+type ArrayPattern struct {
+	Token    token.Token
+	Elements []Pattern
+	Rest     *Identifier
+}
+
+func (a *ArrayPattern) TokenLiteral() string {
+	return a.Token.Literal
+}
+
+func (a *ArrayPattern) String() string {
+	var out bytes.Buffer
+	var elements []string
+
+	for _, e := range a.Elements {
+		elements = append(elements, e.String())
+	}
+	if a.Rest != nil {
+		elements = append(elements, "..."+a.Rest.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+func (a *ArrayPattern) patternNode() {}
+
+type HashPatternPair struct {
+	Key   *StringLiteral
+	Value Pattern
+}
+
+type HashPattern struct {
+	Token token.Token
+	Pairs []*HashPatternPair
+}
+
+func (h *HashPattern) TokenLiteral() string {
+	return h.Token.Literal
+}
+
+func (h *HashPattern) String() string {
+	var pairs []string
+
+	for _, pair := range h.Pairs {
+		key := pair.Key.Value
+		if pair.Key.Token.Type == token.STRING {
+			key = `"` + key + `"`
+		}
+
+		if name, ok := pair.Value.(*Identifier); ok && name.Value == key {
+			pairs = append(pairs, key)
+		} else {
+			pairs = append(pairs, key+": "+pair.Value.String())
+		}
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func (h *HashPattern) patternNode() {}
+
+type AlternativePattern struct {
+	Token        token.Token
+	Alternatives []Pattern
+}
+
+func (a *AlternativePattern) TokenLiteral() string {
+	return a.Token.Literal
+}
+
+func (a *AlternativePattern) String() string {
+	var alternatives []string
+
+	for _, alternative := range a.Alternatives {
+		alternatives = append(alternatives, alternative.String())
+	}
+
+	return strings.Join(alternatives, " | ")
+}
+
+func (a *AlternativePattern) patternNode() {}
+
+func PatternNames(pattern Pattern) []*Identifier {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		if pattern.Value == "_" {
+			return nil
+		}
+		return []*Identifier{pattern}
+	case *ArrayPattern:
+		var names []*Identifier
+		for _, e := range pattern.Elements {
+			names = append(names, PatternNames(e)...)
+		}
+		if pattern.Rest != nil {
+			names = append(names, pattern.Rest)
+		}
+		return names
+	case *HashPattern:
+		var names []*Identifier
+		for _, pair := range pattern.Pairs {
+			names = append(names, PatternNames(pair.Value)...)
+		}
+		return names
+	case *AlternativePattern:
+		var names []*Identifier
+		for _, alternative := range pattern.Alternatives {
+			names = append(names, PatternNames(alternative)...)
+		}
+		return names
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+package ast
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"monkey/token"
+	"sort"
+	"strings"
+)
+
+var childOrder = []string{
+	"name", "pattern", "parameters", "function", "arguments", "subject", "condition", "consequence", "alternative",
+	"left", "index", "start", "end", "right", "key", "value", "returnValue", "expression", "guard", "body",
+	"statements", "elements", "rest", "pairs", "arms", "alternatives",
+}
+
+type printedNode struct {
+	label    string
+	position string
+	children []printedChild
+}
+
+type printedChild struct {
+	field string
+	node  *printedNode
+}
+
+func printable(encoded jsonObject) *printedNode {
+	kind, _ := encoded["kind"].(string)
+	if kind == "" {
+		kind = "HashPair"
+	}
+
+	node := &printedNode{label: kind}
+	if t, ok := encoded["token"].(token.Token); ok {
+		node.position = t.Position.String()
+		if kind == "LetStatement" {
+			node.label += " " + t.Literal
+		}
+	}
+
+	var fields []string
+	for field := range encoded {
+		if field != "kind" && field != "token" {
+			fields = append(fields, field)
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fieldRank(fields[i]) < fieldRank(fields[j])
+	})
+
+	for _, field := range fields {
+		switch value := encoded[field].(type) {
+		case nil:
+		case jsonObject:
+			node.children = append(node.children, printedChild{field: field, node: printable(value)})
+		case []interface{}:
+			for i, element := range value {
+				if element, ok := element.(jsonObject); ok {
+					node.children = append(node.children, printedChild{field: fmt.Sprintf("%s[%d]", field, i), node: printable(element)})
+				}
+			}
+		case string:
+			node.label += " " + fmt.Sprintf("%q", value)
+		default:
+			node.label += " " + fmt.Sprintf("%v", value)
+		}
+	}
+
+	return node
+}
+
+func fieldRank(field string) int {
+	for i, f := range childOrder {
+		if f == field {
+			return i
+		}
+	}
+	return len(childOrder)
+}
+
+func Fprint(w io.Writer, node Node) error {
+	encoded, ok := encode(node).(jsonObject)
+	if !ok {
+		return nil
+	}
+
+	out := bufio.NewWriter(w)
+	fprintTree(out, printable(encoded), "", 0)
+	return out.Flush()
+}
+
+func fprintTree(out *bufio.Writer, node *printedNode, field string, depth int) {
+	out.WriteString(strings.Repeat("  ", depth))
+	if field != "" {
+		out.WriteString(field + ": ")
+	}
+	out.WriteString(node.label)
+	if node.position != "" {
+		out.WriteString(" @" + node.position)
+	}
+	out.WriteString("\n")
+
+	for _, child := range node.children {
+		fprintTree(out, child.node, child.field, depth+1)
+	}
+}
+
+func FprintDot(w io.Writer, node Node) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("digraph ast {\n")
+	out.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+
+	if encoded, ok := encode(node).(jsonObject); ok {
+		next := 0
+		fprintDot(out, printable(encoded), &next)
+	}
+
+	out.WriteString("}\n")
+	return out.Flush()
+}
+
+func fprintDot(out *bufio.Writer, node *printedNode, next *int) int {
+	id := *next
+	*next++
+
+	label := node.label
+	if node.position != "" {
+		label += "\n" + node.position
+	}
+	fmt.Fprintf(out, "  n%d [label=%q];\n", id, label)
+
+	for _, child := range node.children {
+		childID := fprintDot(out, child.node, next)
+		fmt.Fprintf(out, "  n%d -> n%d [label=%q];\n", id, childID, child.field)
+	}
+
+	return id
+}
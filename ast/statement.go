@@ -11,9 +11,10 @@ type Statement interface {
 }
 
 type LetStatement struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token
+	Name    *Identifier
+	Pattern Pattern
+	Value   Expression
 }
 
 func (l *LetStatement) statementNode() {}
@@ -24,7 +25,11 @@ func (l *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(l.TokenLiteral() + " ")
-	out.WriteString(l.Name.String())
+	if l.Pattern != nil {
+		out.WriteString(l.Pattern.String())
+	} else {
+		out.WriteString(l.Name.String())
+	}
 	out.WriteString(" = ")
 	if l.Value != nil {
 		out.WriteString(l.Value.String())
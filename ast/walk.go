@@ -0,0 +1,100 @@
+package ast
+
+func Inspect(node Node, visit func(Node) bool) {
+	if node == nil || !visit(node) {
+		return
+	}
+
+	inspect := func(children ...Node) {
+		for _, child := range children {
+			if child != nil {
+				Inspect(child, visit)
+			}
+		}
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		for _, statement := range node.Statements {
+			inspect(statement)
+		}
+	case *LetStatement:
+		if node.Pattern != nil {
+			inspect(node.Pattern)
+		} else if node.Name != nil {
+			inspect(node.Name)
+		}
+		inspect(node.Value)
+	case *ReturnStatement:
+		inspect(node.ReturnValue)
+	case *ExpressionStatement:
+		inspect(node.Expression)
+	case *BlockStatement:
+		for _, statement := range node.Statements {
+			inspect(statement)
+		}
+	case *PrefixExpression:
+		inspect(node.Right)
+	case *InfixExpression:
+		inspect(node.Left, node.Right)
+	case *IfExpression:
+		inspect(node.Condition)
+		if node.Consequence != nil {
+			inspect(node.Consequence)
+		}
+		if node.Alternative != nil {
+			inspect(node.Alternative)
+		}
+	case *FunctionLiteral:
+		for _, parameter := range node.Parameters {
+			inspect(parameter)
+		}
+		if node.Body != nil {
+			inspect(node.Body)
+		}
+	case *CallExpression:
+		inspect(node.Function)
+		for _, argument := range node.Arguments {
+			inspect(argument)
+		}
+	case *ArrayLiteral:
+		for _, element := range node.Elements {
+			inspect(element)
+		}
+	case *IndexExpression:
+		inspect(node.Left, node.Index)
+	case *SliceExpression:
+		inspect(node.Left, node.Start, node.End)
+	case *HashLiteral:
+		for _, pair := range node.Pairs {
+			inspect(pair.Key, pair.Value)
+		}
+	case *MatchExpression:
+		inspect(node.Subject)
+		for _, arm := range node.Arms {
+			inspect(arm)
+		}
+	case *MatchArm:
+		inspect(node.Pattern, node.Guard)
+		if node.Body != nil {
+			inspect(node.Body)
+		}
+	case *LiteralPattern:
+		inspect(node.Value)
+	case *ArrayPattern:
+		for _, element := range node.Elements {
+			inspect(element)
+		}
+		if node.Rest != nil {
+			inspect(node.Rest)
+		}
+	case *HashPattern:
+		for _, pair := range node.Pairs {
+			inspect(pair.Key, pair.Value)
+		}
+	case *AlternativePattern:
+		for _, alternative := range node.Alternatives {
+			inspect(alternative)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/token"
+)
+
+func tokensCommand(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("tokens", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	file := flags.Arg(0)
+	if file == "" {
+		file = "-"
+	}
+
+	reader, err := openSource(file, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer reader.Close()
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+
+	l := lexer.NewReader(reader)
+	for {
+		t := l.NextToken()
+		if err := l.Err(); err != nil {
+			out.Flush()
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+
+		fmt.Fprintf(out, "%-8s %-10s %q\n", t.Position, t.Type, t.Literal)
+		if t.Type == token.EOF {
+			return 0
+		}
+	}
+}
+
+func astCommand(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "tree", "output format : tree or dot")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "tree" && *format != "dot" {
+		fmt.Fprintf(stderr, "unknown format : %s\n", *format)
+		return 2
+	}
+
+	program, ok := parseFile(flags.Arg(0), stdin, stderr)
+	if !ok {
+		return 1
+	}
+
+	print := ast.Fprint
+	if *format == "dot" {
+		print = ast.FprintDot
+	}
+	if err := print(stdout, program); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
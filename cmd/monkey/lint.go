@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"monkey/lexer"
+	"monkey/lint"
+	"monkey/parser"
+	"os"
+	"strings"
+)
+
+func lintCommand(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	disable := flags.String("disable", "", "comma separated list of rules to disable")
+	enable := flags.String("enable", "", "comma separated list of rules to run, all rules when empty")
+	format := flags.String("format", "text", "output format : text or json")
+	list := flags.Bool("rules", false, "list the available rules")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *list {
+		for _, rule := range lint.Rules() {
+			fmt.Fprintf(stdout, "%-16s %-8s %s\n", rule.Name, rule.Severity, rule.Description)
+		}
+		return 0
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format : %s\n", *format)
+		return 2
+	}
+
+	config, err := lintConfig(*enable, *disable)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	diagnostics := []lint.Diagnostic{}
+	for _, file := range files {
+		fileDiagnostics, err := lintFile(file, stdin, config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+
+		for _, diagnostic := range fileDiagnostics {
+			diagnostic.File = file
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diagnostics); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	} else {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(stdout, diagnostic)
+		}
+	}
+
+	if len(diagnostics) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func lintConfig(enable string, disable string) (lint.Config, error) {
+	config := lint.Config{Disabled: make(map[string]bool)}
+
+	known := make(map[string]bool)
+	for _, rule := range lint.Rules() {
+		known[rule.Name] = true
+	}
+
+	enabled := ruleNames(enable)
+	for _, name := range append(enabled, ruleNames(disable)...) {
+		if !known[name] {
+			return config, fmt.Errorf("unknown rule : %s", name)
+		}
+	}
+
+	if len(enabled) > 0 {
+		for name := range known {
+			config.Disabled[name] = true
+		}
+		for _, name := range enabled {
+			config.Disabled[name] = false
+		}
+	}
+	for _, name := range ruleNames(disable) {
+		config.Disabled[name] = true
+	}
+
+	return config, nil
+}
+
+func ruleNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func lintFile(file string, stdin io.Reader, config lint.Config) ([]lint.Diagnostic, error) {
+	reader, err := openSource(file, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	l := lexer.NewReader(reader)
+	diagnostics := lintProgram(parser.New(l), config)
+	return diagnostics, l.Err()
+}
+
+func lintProgram(p *parser.Parser, config lint.Config) []lint.Diagnostic {
+	program := p.ParseProgram()
+
+	if errors := p.Diagnostics(); len(errors) > 0 {
+		var diagnostics []lint.Diagnostic
+		for _, err := range errors {
+			diagnostics = append(diagnostics, lint.Diagnostic{
+				Line:     err.Position.Line,
+				Column:   err.Position.Column,
+				Offset:   err.Position.Offset,
+				Rule:     "syntax",
+				Severity: lint.ERROR,
+				Message:  err.Message,
+			})
+		}
+		return diagnostics
+	}
+
+	return lint.Lint(program, config)
+}
+
+func openSource(file string, stdin io.Reader) (io.ReadCloser, error) {
+	if file == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(file)
+}
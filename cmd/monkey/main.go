@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"monkey/lsp"
+	"monkey/repl"
+	"os"
+)
+
+func main() {
+	noColor := flag.Bool("no-color", false, "disable colored output in the REPL")
+	optimize := flag.Bool("optimize", false, "fold constants and remove dead branches before evaluation")
+	dumpAST := flag.Bool("dump-ast", false, "print the program of each REPL input before evaluating it")
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "":
+		fmt.Printf("Monkey Pogramming Language Interpreter \n\n")
+		repl.StartWithOptions(os.Stdin, os.Stdout, repl.Options{
+			NoColor:  *noColor || os.Getenv("NO_COLOR") != "",
+			Optimize: *optimize,
+			DumpAST:  *dumpAST,
+		})
+	case "lsp":
+		if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+			fmt.Fprintf(os.Stderr, "lsp : %s\n", err)
+			os.Exit(1)
+		}
+	case "lint":
+		os.Exit(lintCommand(flag.Args()[1:], os.Stdin, os.Stdout, os.Stderr))
+	case "parse":
+		os.Exit(parseCommand(flag.Args()[1:], os.Stdin, os.Stdout, os.Stderr))
+	case "tokens":
+		os.Exit(tokensCommand(flag.Args()[1:], os.Stdin, os.Stdout, os.Stderr))
+	case "ast":
+		os.Exit(astCommand(flag.Args()[1:], os.Stdin, os.Stdout, os.Stderr))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command : %s\n", flag.Arg(0))
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+)
+
+func parseCommand(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("parse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the syntax tree as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	program, ok := parseFile(flags.Arg(0), stdin, stderr)
+	if !ok {
+		return 1
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(program); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintln(stdout, program.String())
+	return 0
+}
+
+func parseFile(file string, stdin io.Reader, stderr io.Writer) (*ast.Program, bool) {
+	if file == "" {
+		file = "-"
+	}
+
+	source, err := openSource(file, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, false
+	}
+	defer source.Close()
+
+	excerpts := parser.NewExcerptReader(source)
+	l := lexer.NewReader(excerpts)
+	p := parser.New(l)
+	excerpts.Track(p)
+	program := p.ParseProgram()
+
+	if err := l.Err(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, false
+	}
+
+	if errors := p.Diagnostics(); len(errors) > 0 {
+		lines := excerpts.Lines()
+		for _, err := range errors {
+			if line, ok := lines[err.Position.Line]; ok {
+				fmt.Fprintf(stderr, "%s:%s\n", file, err.DescribeLine(line))
+			} else {
+				fmt.Fprintf(stderr, "%s:%s\n", file, err)
+			}
+		}
+		return nil, false
+	}
+
+	return program, true
+}
@@ -0,0 +1,357 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"monkey/evaluator"
+	"monkey/object"
+	"reflect"
+)
+
+type Function func(args ...interface{}) (interface{}, error)
+
+var (
+	emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+	functionType       = reflect.TypeOf(Function(nil))
+)
+
+func ToObject(value interface{}) (object.Object, error) {
+	if value == nil {
+		return evaluator.NULL, nil
+	}
+
+	if o, ok := value.(object.Object); ok {
+		return o, nil
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return evaluator.TRUE, nil
+		}
+		return evaluator.FALSE, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return object.NewInteger(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("integer overflow : %d", v.Uint())
+		}
+		return object.NewInteger(int64(v.Uint())), nil
+	case reflect.String:
+		return &object.String{Value: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return evaluator.NULL, nil
+		}
+
+		elements := make([]object.Object, v.Len())
+		for i := range elements {
+			element, err := ToObject(v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return object.NewArray(elements), nil
+	case reflect.Map:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+
+		pairs := make(map[object.HashKey]object.HashPair)
+		iterator := v.MapRange()
+		for iterator.Next() {
+			key, err := ToObject(iterator.Key().Interface())
+			if err != nil {
+				return nil, err
+			}
+
+			hashKey, ok := key.(object.Hashable)
+			if !ok {
+				return nil, fmt.Errorf("unusable as hash key : %s", key.Type())
+			}
+
+			element, err := ToObject(iterator.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+
+			pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: element}
+		}
+		return &object.Hash{Pairs: pairs}, nil
+	case reflect.Func:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return builtinFunction(value)
+	default:
+		return nil, fmt.Errorf("unsupported go type : %s", v.Type())
+	}
+}
+
+func toGo(o object.Object, runtime object.Runtime) (interface{}, error) {
+	switch o := o.(type) {
+	case *object.Integer:
+		return o.Value, nil
+	case *object.String:
+		return o.Value, nil
+	case *object.Boolean:
+		return o.Value, nil
+	case *object.Null:
+		return nil, nil
+	case *object.Array:
+		elements := make([]interface{}, o.Len())
+		for i, e := range o.Elements() {
+			element, err := toGo(e, runtime)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return elements, nil
+	case *object.Hash:
+		pairs := make(map[interface{}]interface{}, len(o.Pairs))
+		for _, pair := range o.Pairs {
+			key, err := toGo(pair.Key, runtime)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := toGo(pair.Value, runtime)
+			if err != nil {
+				return nil, err
+			}
+
+			pairs[key] = value
+		}
+		return pairs, nil
+	case *object.Function, *object.Builtin:
+		return callback(o, runtime), nil
+	case *object.Error:
+		return nil, &RuntimeError{Message: o.Message}
+	case *object.LimitError:
+		return nil, &LimitError{Limit: o.Limit, Message: o.Message}
+	default:
+		return nil, fmt.Errorf("unsupported object type : %s", o.Type())
+	}
+}
+
+func callback(function object.Object, runtime object.Runtime) Function {
+	return func(args ...interface{}) (interface{}, error) {
+		arguments := make([]object.Object, len(args))
+		for i, arg := range args {
+			o, err := ToObject(arg)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := runtime.Allocate(evaluator.SizeOf(o)); err != nil {
+				return result(err, runtime)
+			}
+			arguments[i] = o
+		}
+
+		return result(apply(runtime, function, arguments), runtime)
+	}
+}
+
+func apply(runtime object.Runtime, function object.Object, args []object.Object) object.Object {
+	if e, ok := runtime.(*evaluator.Evaluator); ok {
+		return e.ApplyContext(context.Background(), function, args...)
+	}
+	return runtime.Apply(function, args...)
+}
+
+func builtinFunction(function interface{}) (*object.Builtin, error) {
+	switch function := function.(type) {
+	case *object.Builtin:
+		return function, nil
+	case object.BuiltinFunction:
+		return &object.Builtin{Function: function}, nil
+	case func(object.Runtime, ...object.Object) object.Object:
+		return &object.Builtin{Function: function}, nil
+	}
+
+	v := reflect.ValueOf(function)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("builtin must be a function, got %T", function)
+	}
+
+	return wrapFunction(v)
+}
+
+func wrapFunction(function reflect.Value) (*object.Builtin, error) {
+	t := function.Type()
+
+	returnsError := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+	values := t.NumOut()
+	if returnsError {
+		values--
+	}
+
+	if values > 1 {
+		return nil, fmt.Errorf("unsupported function signature : %s", t)
+	}
+
+	return &object.Builtin{
+		Arity:    t.NumIn(),
+		HasArity: !t.IsVariadic(),
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			in, err := functionArguments(t, args, runtime)
+			if err != nil {
+				return &object.Error{Message: err.Error()}
+			}
+
+			out := function.Call(in)
+
+			if returnsError {
+				if err, _ := out[len(out)-1].Interface().(error); err != nil {
+					return errorObject(err)
+				}
+			}
+
+			if values == 0 {
+				return evaluator.NULL
+			}
+
+			o, err := ToObject(out[0].Interface())
+			if err != nil {
+				return &object.Error{Message: err.Error()}
+			}
+
+			if err := runtime.Allocate(evaluator.SizeOf(o)); err != nil {
+				return err
+			}
+			return o
+		},
+	}, nil
+}
+
+func errorObject(err error) object.Object {
+	var limitError *LimitError
+	if errors.As(err, &limitError) {
+		return &object.LimitError{Limit: limitError.Limit, Message: limitError.Message}
+	}
+	return &object.Error{Message: err.Error()}
+}
+
+func functionArguments(t reflect.Type, args []object.Object, runtime object.Runtime) ([]reflect.Value, error) {
+	fixed := t.NumIn()
+	if t.IsVariadic() {
+		fixed--
+	}
+
+	if len(args) < fixed || (!t.IsVariadic() && len(args) != fixed) {
+		return nil, fmt.Errorf("wrong number of arguments. got=%d, want=%d", len(args), fixed)
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var parameter reflect.Type
+		if i < fixed {
+			parameter = t.In(i)
+		} else {
+			parameter = t.In(fixed).Elem()
+		}
+
+		value, err := toValue(arg, parameter, runtime)
+		if err != nil {
+			return nil, err
+		}
+		in[i] = value
+	}
+
+	return in, nil
+}
+
+func toValue(o object.Object, t reflect.Type, runtime object.Runtime) (reflect.Value, error) {
+	if t == emptyInterfaceType {
+		value, err := toGo(o, runtime)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if value == nil {
+			return reflect.Zero(t), nil
+		}
+		return reflect.ValueOf(value), nil
+	}
+
+	if reflect.TypeOf(o).AssignableTo(t) {
+		return reflect.ValueOf(o), nil
+	}
+
+	if o == evaluator.NULL {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Ptr, reflect.Interface, reflect.Func:
+			return reflect.Zero(t), nil
+		}
+	}
+
+	switch o := o.(type) {
+	case *object.Integer:
+		value := reflect.New(t).Elem()
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value.OverflowInt(o.Value) {
+				return reflect.Value{}, fmt.Errorf("integer overflow : %d does not fit in %s", o.Value, t)
+			}
+			value.SetInt(o.Value)
+			return value, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if o.Value < 0 || value.OverflowUint(uint64(o.Value)) {
+				return reflect.Value{}, fmt.Errorf("integer overflow : %d does not fit in %s", o.Value, t)
+			}
+			value.SetUint(uint64(o.Value))
+			return value, nil
+		}
+	case *object.String:
+		if t.Kind() == reflect.String {
+			return reflect.ValueOf(o.Value).Convert(t), nil
+		}
+	case *object.Boolean:
+		if t.Kind() == reflect.Bool {
+			return reflect.ValueOf(o.Value).Convert(t), nil
+		}
+	case *object.Array:
+		if t.Kind() == reflect.Slice {
+			value := reflect.MakeSlice(t, o.Len(), o.Len())
+			for i, e := range o.Elements() {
+				element, err := toValue(e, t.Elem(), runtime)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				value.Index(i).Set(element)
+			}
+			return value, nil
+		}
+	case *object.Hash:
+		if t.Kind() == reflect.Map {
+			value := reflect.MakeMapWithSize(t, len(o.Pairs))
+			for _, pair := range o.Pairs {
+				key, err := toValue(pair.Key, t.Key(), runtime)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				element, err := toValue(pair.Value, t.Elem(), runtime)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				value.SetMapIndex(key, element)
+			}
+			return value, nil
+		}
+	case *object.Function, *object.Builtin:
+		if t == functionType {
+			return reflect.ValueOf(callback(o, runtime)), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot convert %s to %s", o.Type(), t)
+}
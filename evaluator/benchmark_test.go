@@ -0,0 +1,66 @@
+package evaluator
+
+import (
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+)
+
+func benchmarkProgram(b *testing.B, input string) {
+	program := parser.New(lexer.New(input)).ParseProgram()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		result := Evaluate(program, object.NewEnvironment())
+		if IsError(result) {
+			b.Fatalf("evaluation failed : %s", result.Inspect())
+		}
+	}
+}
+
+func BenchmarkFibonacci(b *testing.B) {
+	benchmarkProgram(b, `
+let fibonacci = fn(n) {
+  if (n < 2) { return n; }
+  fibonacci(n - 1) + fibonacci(n - 2)
+};
+fibonacci(20);
+`)
+}
+
+func BenchmarkArrayBuilding(b *testing.B) {
+	benchmarkProgram(b, `
+let build = fn(array, n) {
+  if (n == 0) { return array; }
+  build(push(array, n), n - 1)
+};
+len(build([], 500));
+`)
+}
+
+func BenchmarkStringConcatenation(b *testing.B) {
+	benchmarkProgram(b, `
+let repeat = fn(s, n) {
+  if (n == 0) { return s; }
+  repeat(s + "monkey", n - 1)
+};
+len(repeat("", 500));
+`)
+}
+
+func BenchmarkArrayTraversal(b *testing.B) {
+	benchmarkProgram(b, `
+let build = fn(array, n) {
+  if (n == 0) { return array; }
+  build(push(array, n), n - 1)
+};
+let sum = fn(array, total) {
+  if (len(array) == 0) { return total; }
+  sum(array[1:], total + array[0])
+};
+sum(build([], 500), 0);
+`)
+}
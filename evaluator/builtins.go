@@ -1,26 +1,62 @@
 package evaluator
 
-import "monkey/object"
+import (
+	"monkey/object"
+	"sort"
+)
 
-var builtins = map[string]*object.Builtin{
+type Builtins map[string]*object.Builtin
+
+func NewBuiltins() Builtins {
+	builtins := make(Builtins, len(defaultBuiltins))
+	for name, builtin := range defaultBuiltins {
+		builtins[name] = builtin
+	}
+	return builtins
+}
+
+func (b Builtins) Register(name string, function object.BuiltinFunction) {
+	b[name] = &object.Builtin{Function: function}
+}
+
+func (b Builtins) RegisterArity(name string, arity int, function object.BuiltinFunction) {
+	b[name] = &object.Builtin{Function: function, Arity: arity, HasArity: true}
+}
+
+func (b Builtins) Names() []string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var defaultBuiltins = Builtins{
 	"len": {
-		Function: func(args ...object.Object) object.Object {
+		Arity:    1,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
 
 			switch argument := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(argument.Value))}
+				return object.NewInteger(int64(len(argument.Value)))
 			case *object.Array:
-				return &object.Integer{Value: int64(len(argument.Elements))}
+				return object.NewInteger(int64(argument.Len()))
+			case *object.Hash:
+				return object.NewInteger(int64(len(argument.Pairs)))
 			default:
 				return newError("argument to `len` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 	"push": {
-		Function: func(args ...object.Object) object.Object {
+		Arity:    2,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
 			if len(args) != 2 {
 				return newError("wrong number of arguments. got=%d, want=2", len(args))
 			}
@@ -29,14 +65,310 @@ var builtins = map[string]*object.Builtin{
 				return newError("argument to push must be ARRAY, got %s", args[0].Type())
 			}
 
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
+			if err := runtime.Allocate(arraySize(1)); err != nil {
+				return err
+			}
+
+			return args[0].(*object.Array).Push(args[1])
+		},
+	},
+	"first": {
+		Arity:    1,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, err := arrayArgument("first", args)
+			if err != nil {
+				return err
+			}
+			if arr.Len() == 0 {
+				return NULL
+			}
+			return arr.At(0)
+		},
+	},
+	"last": {
+		Arity:    1,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, err := arrayArgument("last", args)
+			if err != nil {
+				return err
+			}
+			if arr.Len() == 0 {
+				return NULL
+			}
+			return arr.At(arr.Len() - 1)
+		},
+	},
+	"rest": {
+		Arity:    1,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, err := arrayArgument("rest", args)
+			if err != nil {
+				return err
+			}
+			if arr.Len() == 0 {
+				return NULL
+			}
+
+			if err := runtime.Allocate(arraySize(0)); err != nil {
+				return err
+			}
+			return arr.Rest()
+		},
+	},
+	"map": {
+		Arity:    2,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, function, err := arrayAndFunctionArguments("map", args)
+			if err != nil {
+				return err
+			}
+
+			if err := runtime.Allocate(arraySize(arr.Len())); err != nil {
+				return err
+			}
+
+			elements := make([]object.Object, arr.Len())
+			for i, e := range arr.Elements() {
+				result := call(runtime, function, e)
+				if IsError(result) {
+					return result
+				}
+				elements[i] = result
+			}
+
+			return object.NewArray(elements)
+		},
+	},
+	"filter": {
+		Arity:    2,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, function, err := arrayAndFunctionArguments("filter", args)
+			if err != nil {
+				return err
+			}
+
+			elements := []object.Object{}
+			for _, e := range arr.Elements() {
+				result := call(runtime, function, e)
+				if IsError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					elements = append(elements, e)
+				}
+			}
+
+			if err := runtime.Allocate(arraySize(len(elements))); err != nil {
+				return err
+			}
+
+			return object.NewArray(elements)
+		},
+	},
+	"reduce": {
+		Arity:    3,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3", len(args))
+			}
+
+			arr, function, err := arrayAndFunctionArguments("reduce", []object.Object{args[0], args[2]})
+			if err != nil {
+				return err
+			}
+
+			accumulator := args[1]
+			for _, e := range arr.Elements() {
+				accumulator = call(runtime, function, accumulator, e)
+				if IsError(accumulator) {
+					return accumulator
+				}
+			}
+
+			return accumulator
+		},
+	},
+	"each": {
+		Arity:    2,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, function, err := arrayAndFunctionArguments("each", args)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range arr.Elements() {
+				result := call(runtime, function, e)
+				if IsError(result) {
+					return result
+				}
+			}
+
+			return NULL
+		},
+	},
+	"any": {
+		Arity:    2,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, function, err := arrayAndFunctionArguments("any", args)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range arr.Elements() {
+				result := call(runtime, function, e)
+				if IsError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return TRUE
+				}
+			}
+
+			return FALSE
+		},
+	},
+	"all": {
+		Arity:    2,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, function, err := arrayAndFunctionArguments("all", args)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range arr.Elements() {
+				result := call(runtime, function, e)
+				if IsError(result) {
+					return result
+				}
+				if !isTruthy(result) {
+					return FALSE
+				}
+			}
+
+			return TRUE
+		},
+	},
+	"find": {
+		Arity:    2,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, function, err := arrayAndFunctionArguments("find", args)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range arr.Elements() {
+				result := call(runtime, function, e)
+				if IsError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return e
+				}
+			}
+
+			return NULL
+		},
+	},
+	"sort_by": {
+		Arity:    2,
+		HasArity: true,
+		Function: func(runtime object.Runtime, args ...object.Object) object.Object {
+			arr, function, err := arrayAndFunctionArguments("sort_by", args)
+			if err != nil {
+				return err
+			}
+
+			keys := make([]object.Object, arr.Len())
+			for i, e := range arr.Elements() {
+				key := call(runtime, function, e)
+				if IsError(key) {
+					return key
+				}
+				if key.Type() != object.INTEGER_OBJECT && key.Type() != object.STRING_OBJECT {
+					return newError("sort key must be INTEGER or STRING, got %s", key.Type())
+				}
+				if i > 0 && key.Type() != keys[0].Type() {
+					return newError("sort keys must have the same type : %s, %s", keys[0].Type(), key.Type())
+				}
+				keys[i] = key
+			}
+
+			indexes := make([]int, arr.Len())
+			for i := range indexes {
+				indexes[i] = i
+			}
+
+			sort.SliceStable(indexes, func(i, j int) bool {
+				return lessKey(keys[indexes[i]], keys[indexes[j]])
+			})
 
-			newElements := make([]object.Object, length+1, length+1)
-			copy(newElements, arr.Elements)
-			newElements[length] = args[1]
+			if err := runtime.Allocate(arraySize(arr.Len())); err != nil {
+				return err
+			}
+
+			elements := make([]object.Object, arr.Len())
+			for i, index := range indexes {
+				elements[i] = arr.At(index)
+			}
 
-			return &object.Array{Elements: newElements}
+			return object.NewArray(elements)
 		},
 	},
 }
+
+func call(runtime object.Runtime, function object.Object, args ...object.Object) object.Object {
+	if result := runtime.Apply(function, args...); result != nil {
+		return result
+	}
+	return NULL
+}
+
+func arrayArgument(name string, args []object.Object) (*object.Array, *object.Error) {
+	if len(args) != 1 {
+		return nil, newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	return arr, nil
+}
+
+func arrayAndFunctionArguments(name string, args []object.Object) (*object.Array, object.Object, *object.Error) {
+	if len(args) != 2 {
+		return nil, nil, newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, nil, newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+
+	if args[1].Type() != object.FUNCTION_OBJECT && args[1].Type() != object.BUILTIN_OBJECT {
+		return nil, nil, newError("argument to `%s` must be FUNCTION, got %s", name, args[1].Type())
+	}
+
+	return arr, args[1], nil
+}
+
+func lessKey(left object.Object, right object.Object) bool {
+	switch left := left.(type) {
+	case *object.Integer:
+		return left.Value < right.(*object.Integer).Value
+	case *object.String:
+		return left.Value < right.(*object.String).Value
+	default:
+		return false
+	}
+}
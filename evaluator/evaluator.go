@@ -1,9 +1,13 @@
 package evaluator
 
 import (
+	"context"
 	"fmt"
 	"monkey/ast"
 	"monkey/object"
+	"monkey/resolver"
+	"monkey/token"
+	"strings"
 )
 
 var (
@@ -12,105 +16,195 @@ var (
 	NULL  = &object.Null{}
 )
 
+type Evaluator struct {
+	builtins Builtins
+	limits   Limits
+
+	ctx       context.Context
+	steps     int
+	depth     int
+	allocated int64
+	exceeded  *object.LimitError
+	running   bool
+}
+
+func New(builtins Builtins) *Evaluator {
+	if builtins == nil {
+		builtins = defaultBuiltins
+	}
+	return &Evaluator{builtins: builtins}
+}
+
 func Evaluate(node ast.Node, environment *object.Environment) object.Object {
+	return New(nil).Evaluate(node, environment)
+}
+
+func (e *Evaluator) Evaluate(node ast.Node, environment *object.Environment) object.Object {
+	if err := e.step(); err != nil {
+		return err
+	}
+
 	switch node := node.(type) {
 	case *ast.Program:
-		return evaluateProgram(node.Statements, environment)
+		if err := e.resolve(node, environment); err != nil {
+			return err
+		}
+		return e.evaluateProgram(node.Statements, environment)
 	case *ast.ExpressionStatement:
-		return Evaluate(node.Expression, environment)
+		return e.Evaluate(node.Expression, environment)
 	case *ast.PrefixExpression:
-		right := Evaluate(node.Right, environment)
-		if isError(right) {
+		right := e.Evaluate(node.Right, environment)
+		if IsError(right) {
 			return right
 		}
 		return evaluatePrefixExpression(node.Operator, right)
 	case *ast.NumberLiteral:
-		return &object.Integer{Value: node.Value}
+		return object.NewInteger(node.Value)
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.InfixExpression:
-		left := Evaluate(node.Left, environment)
-		right := Evaluate(node.Right, environment)
-
-		if isError(left) {
+		left := e.Evaluate(node.Left, environment)
+		if IsError(left) {
 			return left
 		}
-		if isError(right) {
+
+		right := e.Evaluate(node.Right, environment)
+		if IsError(right) {
 			return right
 		}
 
-		return evaluateInfixExpression(node.Operator, Evaluate(node.Left, environment), Evaluate(node.Right, environment))
+		result := evaluateInfixExpression(node.Operator, left, right)
+		if result.Type() == object.STRING_OBJECT {
+			return e.track(result)
+		}
+		return result
 	case *ast.BlockStatement:
-		return evaluateBlockStatement(node, environment)
+		return e.evaluateBlockStatement(node, environment)
 	case *ast.IfExpression:
-		condition := Evaluate(node.Condition, environment)
-		if isError(condition) {
+		condition := e.Evaluate(node.Condition, environment)
+		if IsError(condition) {
 			return condition
 		}
-		return evaluateIfExpression(node, environment)
+		return e.evaluateIfExpression(node, condition, environment)
 	case *ast.ReturnStatement:
-		value := Evaluate(node.ReturnValue, environment)
-		if isError(value) {
+		value := e.Evaluate(node.ReturnValue, environment)
+		if IsError(value) {
 			return value
 		}
-		return &object.ReturnValue{Value: Evaluate(node.ReturnValue, environment)}
+		return &object.ReturnValue{Value: value}
 	case *ast.LetStatement:
-		value := Evaluate(node.Value, environment)
-		if isError(value) {
-			return value
+		return e.evaluateLetStatement(node, environment)
+	case *ast.MatchExpression:
+		subject := e.Evaluate(node.Subject, environment)
+		if IsError(subject) {
+			return subject
 		}
-		environment.Set(node.Name.Value, value)
+		return e.evaluateMatchExpression(node, subject, environment)
 	case *ast.Identifier:
-		return evaluateIdentifier(node, environment)
+		return e.evaluateIdentifier(node, environment)
 	case *ast.FunctionLiteral:
-		return &object.Function{Parameters: node.Parameters, Environment: environment, Body: node.Body}
+		return &object.Function{Parameters: node.Parameters, Environment: environment, Body: node.Body, Slots: node.Slots}
 	case *ast.CallExpression:
-		function := Evaluate(node.Function, environment)
-		if isError(function) {
+		function := e.Evaluate(node.Function, environment)
+		if IsError(function) {
 			return function
 		}
-		args := evaluateExpressions(node.Arguments, environment)
-		if len(args) == 1 && isError(args[0]) {
+		args := e.evaluateExpressions(node.Arguments, environment)
+		if len(args) == 1 && IsError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		if function, ok := function.(*object.Function); ok && node.Tail {
+			return &tailCall{function: function, args: args}
+		}
+		return e.applyFunction(function, args)
 	case *ast.StringLiteral:
-		return &object.String{Value: node.Value}
+		s, allocated := object.NewInternedString(node.Value)
+		if allocated {
+			return e.track(s)
+		}
+		return s
 	case *ast.ArrayLiteral:
-		elements := evaluateExpressions(node.Elements, environment)
-		if len(elements) == 1 && isError(elements[0]) {
+		elements := e.evaluateExpressions(node.Elements, environment)
+		if len(elements) == 1 && IsError(elements[0]) {
 			return elements[0]
 		}
-		return &object.Array{Elements: elements}
+		return e.track(object.NewArray(elements))
+	case *ast.HashLiteral:
+		return e.evaluateHashLiteral(node, environment)
 	case *ast.IndexExpression:
-		left := Evaluate(node.Left, environment)
-		if isError(left) {
+		left := e.Evaluate(node.Left, environment)
+		if IsError(left) {
 			return left
 		}
 
-		index := Evaluate(node.Index, environment)
-		if isError(index) {
+		index := e.Evaluate(node.Index, environment)
+		if IsError(index) {
 			return index
 		}
 
-		return evaluateIndexExpression(left, index)
+		result := evaluateIndexExpression(left, index)
+		if left.Type() == object.STRING_OBJECT {
+			return e.track(result)
+		}
+		return result
+	case *ast.SliceExpression:
+		left := e.Evaluate(node.Left, environment)
+		if IsError(left) {
+			return left
+		}
+
+		start := e.evaluateSliceBound(node.Start, environment)
+		if IsError(start) {
+			return start
+		}
+
+		end := e.evaluateSliceBound(node.End, environment)
+		if IsError(end) {
+			return end
+		}
+
+		return e.track(evaluateSliceExpression(left, start, end))
 	}
 	return nil
 }
 
-func applyFunction(f object.Object, args []object.Object) object.Object {
+func (e *Evaluator) applyFunction(f object.Object, args []object.Object) object.Object {
 	switch function := f.(type) {
 	case *object.Function:
-		extendedEnvironment := extendFunctionEnvironment(function, args)
-		evaluated := Evaluate(function.Body, extendedEnvironment)
-		return unwrapReturnValue(evaluated)
+		if err := e.enter(); err != nil {
+			e.leave()
+			return err
+		}
+		defer e.leave()
+
+		for {
+			if len(args) != len(function.Parameters) {
+				return newError("wrong number of arguments. got=%d, want=%d", len(args), len(function.Parameters))
+			}
+			if err := e.Allocate(environmentSizeOf(len(args))); err != nil {
+				return err
+			}
+
+			extendedEnvironment := extendFunctionEnvironment(function, args)
+			evaluated := unwrapReturnValue(e.Evaluate(function.Body, extendedEnvironment))
+
+			call, ok := evaluated.(*tailCall)
+			if !ok {
+				return evaluated
+			}
+			function, args = call.function, call.args
+		}
 	case *object.Builtin:
-		return function.Function(args...)
+		return function.Function(e, args...)
 	default:
 		return newError("not a function : %s", f.Type())
 	}
 }
 
+func (e *Evaluator) Apply(function object.Object, args ...object.Object) object.Object {
+	return e.applyFunction(function, args)
+}
+
 func unwrapReturnValue(o object.Object) object.Object {
 	if returnValue, ok := o.(*object.ReturnValue); ok {
 		return returnValue.Value
@@ -119,21 +213,210 @@ func unwrapReturnValue(o object.Object) object.Object {
 }
 
 func extendFunctionEnvironment(function *object.Function, args []object.Object) *object.Environment {
-	environment := object.NewEnclosedEnvironment(function.Environment)
+	environment := object.NewFrame(function.Environment, function.Slots)
 
 	for i, param := range function.Parameters {
-		environment.Set(param.Value, args[i])
+		bind(environment, binding{name: param, value: args[i]}, false)
 	}
 
 	return environment
 }
 
-func evaluateExpressions(expressions []ast.Expression, environment *object.Environment) []object.Object {
-	var result []object.Object
+type binding struct {
+	name  *ast.Identifier
+	value object.Object
+}
+
+func bind(environment *object.Environment, b binding, constant bool) {
+	switch {
+	case b.name.Binding != nil && constant:
+		environment.SetConstantAt(b.name.Binding.Slot, b.value)
+	case b.name.Binding != nil:
+		environment.SetAt(b.name.Binding.Slot, b.value)
+	case constant:
+		environment.SetConstant(b.name.Value, b.value)
+	default:
+		environment.Set(b.name.Value, b.value)
+	}
+}
+
+func isConstant(environment *object.Environment, name *ast.Identifier) bool {
+	if name.Binding != nil {
+		return environment.IsConstantAt(name.Binding.Slot)
+	}
+	return environment.IsConstant(name.Value)
+}
+
+func (e *Evaluator) evaluateLetStatement(node *ast.LetStatement, environment *object.Environment) object.Object {
+	value := e.Evaluate(node.Value, environment)
+	if IsError(value) {
+		return value
+	}
+
+	bindings := []binding{}
+	if node.Pattern != nil {
+		destructured, err := destructure(node.Pattern, value)
+		if err != nil {
+			return err
+		}
+		bindings = destructured
+	} else {
+		bindings = append(bindings, binding{name: node.Name, value: value})
+	}
+
+	for _, b := range bindings {
+		if isConstant(environment, b.name) {
+			return newError("cannot reassign constant : %s", b.name.Value)
+		}
+	}
+
+	for _, b := range bindings {
+		bind(environment, b, node.Token.Type == token.CONST)
+	}
+
+	return nil
+}
+
+func destructure(pattern ast.Pattern, value object.Object) ([]binding, *object.Error) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value == "_" {
+			return nil, nil
+		}
+		return []binding{{name: pattern, value: value}}, nil
+	case *ast.LiteralPattern:
+		if !object.Equal(literalValue(pattern.Value), value) {
+			return nil, newError("%s does not match pattern %s", value.Inspect(), pattern.String())
+		}
+		return nil, nil
+	case *ast.ArrayPattern:
+		return destructureArray(pattern, value)
+	case *ast.HashPattern:
+		return destructureHash(pattern, value)
+	case *ast.AlternativePattern:
+		var err *object.Error
+		for _, alternative := range pattern.Alternatives {
+			var bindings []binding
+			bindings, err = destructure(alternative, value)
+			if err == nil {
+				return bindings, nil
+			}
+		}
+		return nil, err
+	default:
+		return nil, newError("unknown pattern : %s", pattern.String())
+	}
+}
+
+func literalValue(expression ast.Expression) object.Object {
+	switch expression := expression.(type) {
+	case *ast.NumberLiteral:
+		return object.NewInteger(expression.Value)
+	case *ast.StringLiteral:
+		return object.InternString(expression.Value)
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(expression.Value)
+	case *ast.PrefixExpression:
+		if number, ok := expression.Right.(*ast.NumberLiteral); ok && expression.Operator == "-" {
+			return object.NewInteger(-number.Value)
+		}
+	}
+	return NULL
+}
+
+func destructureArray(pattern *ast.ArrayPattern, value object.Object) ([]binding, *object.Error) {
+	array, ok := value.(*object.Array)
+	if !ok {
+		return nil, newError("cannot destructure %s with array pattern %s", value.Type(), pattern.String())
+	}
+
+	length := array.Len()
+	want := len(pattern.Elements)
+
+	if length < want {
+		return nil, newError("not enough values to destructure. got=%d, want=%d", length, want)
+	}
+	if length > want && pattern.Rest == nil {
+		return nil, newError("too many values to destructure. got=%d, want=%d", length, want)
+	}
+
+	var bindings []binding
+	for i, element := range pattern.Elements {
+		destructured, err := destructure(element, array.At(i))
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, destructured...)
+	}
+
+	if pattern.Rest != nil {
+		bindings = append(bindings, binding{name: pattern.Rest, value: array.Slice(want, length)})
+	}
+
+	return bindings, nil
+}
+
+func destructureHash(pattern *ast.HashPattern, value object.Object) ([]binding, *object.Error) {
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		return nil, newError("cannot destructure %s with hash pattern %s", value.Type(), pattern.String())
+	}
+
+	var bindings []binding
+	for _, pair := range pattern.Pairs {
+		found, ok := hash.Pairs[object.InternString(pair.Key.Value).HashKey()]
+		if !ok {
+			return nil, newError("missing key to destructure : %s", pair.Key.Value)
+		}
+
+		destructured, err := destructure(pair.Value, found.Value)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, destructured...)
+	}
+
+	return bindings, nil
+}
+
+func (e *Evaluator) evaluateMatchExpression(node *ast.MatchExpression, subject object.Object, environment *object.Environment) object.Object {
+	for _, arm := range node.Arms {
+		bindings, err := destructure(arm.Pattern, subject)
+		if err != nil {
+			continue
+		}
+
+		if err := e.Allocate(environmentSizeOf(len(bindings))); err != nil {
+			return err
+		}
+
+		armEnvironment := object.NewFrame(environment, arm.Slots)
+		for _, b := range bindings {
+			bind(armEnvironment, b, false)
+		}
+
+		if arm.Guard != nil {
+			guard := e.Evaluate(arm.Guard, armEnvironment)
+			if IsError(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		return e.Evaluate(arm.Body, armEnvironment)
+	}
+
+	return NULL
+}
+
+func (e *Evaluator) evaluateExpressions(expressions []ast.Expression, environment *object.Environment) []object.Object {
+	result := make([]object.Object, 0, len(expressions))
 
-	for _, e := range expressions {
-		evaluated := Evaluate(e, environment)
-		if isError(evaluated) {
+	for _, expression := range expressions {
+		evaluated := e.Evaluate(expression, environment)
+		if IsError(evaluated) {
 			return []object.Object{evaluated}
 		}
 		result = append(result, evaluated)
@@ -142,32 +425,58 @@ func evaluateExpressions(expressions []ast.Expression, environment *object.Envir
 	return result
 }
 
-func isError(o object.Object) bool {
+func IsError(o object.Object) bool {
 	if o != nil {
-		return o.Type() == object.ERROR_OBJECT
+		return o.Type() == object.ERROR_OBJECT || o.Type() == object.LIMIT_ERROR_OBJECT
 	}
 	return false
 }
 
-func evaluateIdentifier(node *ast.Identifier, environment *object.Environment) object.Object {
+func (e *Evaluator) resolve(program *ast.Program, environment *object.Environment) object.Object {
+	errors := resolver.Resolve(program, func(name string) bool {
+		if _, ok := environment.Get(name); ok {
+			return true
+		}
+		_, ok := e.builtins[name]
+		return ok
+	})
+
+	var messages []string
+	for _, err := range errors {
+		if !err.Warning {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	if len(messages) > 0 {
+		return newError("%s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+func (e *Evaluator) evaluateIdentifier(node *ast.Identifier, environment *object.Environment) object.Object {
+	if node.Binding != nil {
+		if value, ok := environment.GetAt(node.Binding.Depth, node.Binding.Slot); ok {
+			return value
+		}
+	}
+
 	if value, ok := environment.Get(node.Value); ok {
 		return value
 	}
 
-	if value, ok := builtins[node.Value]; ok {
+	if value, ok := e.builtins[node.Value]; ok {
 		return value
 	}
 
-	return newError("identifier not found : " + node.Value)
+	return newError("identifier not found : %s", node.Value)
 }
 
-func evaluateIfExpression(expression *ast.IfExpression, environment *object.Environment) object.Object {
-	condition := Evaluate(expression.Condition, environment)
-
+func (e *Evaluator) evaluateIfExpression(expression *ast.IfExpression, condition object.Object, environment *object.Environment) object.Object {
 	if isTruthy(condition) {
-		return Evaluate(expression.Consequence, environment)
+		return e.Evaluate(expression.Consequence, environment)
 	} else if expression.Alternative != nil {
-		return Evaluate(expression.Alternative, environment)
+		return e.Evaluate(expression.Alternative, environment)
 	} else {
 		return NULL
 	}
@@ -193,9 +502,9 @@ func evaluateInfixExpression(operator string, left object.Object, right object.O
 	case left.Type() == object.STRING_OBJECT && right.Type() == object.STRING_OBJECT:
 		return evaluateStringInfixExpression(operator, left, right)
 	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
+		return nativeBoolToBooleanObject(object.Equal(left, right))
 	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
 	case left.Type() != right.Type():
 		return newError("type mismatch : %s %s %s", left.Type(), operator, right.Type())
 	default:
@@ -204,11 +513,16 @@ func evaluateInfixExpression(operator string, left object.Object, right object.O
 }
 
 func evaluateStringInfixExpression(operator string, left object.Object, right object.Object) object.Object {
-	if operator != "+" {
+	switch operator {
+	case "+":
+		return &object.String{Value: left.(*object.String).Value + right.(*object.String).Value}
+	case "==":
+		return nativeBoolToBooleanObject(object.Equal(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
+	default:
 		return newError("unknown operator : %s %s %s", left.Type(), operator, right.Type())
 	}
-
-	return &object.String{Value: left.(*object.String).Value + right.(*object.String).Value}
 }
 
 func evaluateIntegerInfixExpression(operator string, left object.Object, right object.Object) object.Object {
@@ -217,13 +531,16 @@ func evaluateIntegerInfixExpression(operator string, left object.Object, right o
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftValue + rightValue}
+		return object.NewInteger(leftValue + rightValue)
 	case "-":
-		return &object.Integer{Value: leftValue - rightValue}
+		return object.NewInteger(leftValue - rightValue)
 	case "*":
-		return &object.Integer{Value: leftValue * rightValue}
+		return object.NewInteger(leftValue * rightValue)
 	case "/":
-		return &object.Integer{Value: leftValue / rightValue}
+		if rightValue == 0 {
+			return newError("division by zero")
+		}
+		return object.NewInteger(leftValue / rightValue)
 	case "<":
 		return nativeBoolToBooleanObject(leftValue < rightValue)
 	case ">":
@@ -253,7 +570,7 @@ func evaluateMinusPrefixOperatorExpression(right object.Object) object.Object {
 		return newError("unknown operator : -%s", right.Type())
 	}
 	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	return object.NewInteger(-value)
 }
 
 func evaluateBangOperatorExpression(right object.Object) object.Object {
@@ -276,16 +593,16 @@ func nativeBoolToBooleanObject(value bool) *object.Boolean {
 	return FALSE
 }
 
-func evaluateProgram(statements []ast.Statement, environment *object.Environment) object.Object {
+func (e *Evaluator) evaluateProgram(statements []ast.Statement, environment *object.Environment) object.Object {
 	var result object.Object
 
 	for _, statement := range statements {
-		result = Evaluate(statement, environment)
+		result = e.Evaluate(statement, environment)
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
 			return result.Value
-		case *object.Error:
+		case *object.Error, *object.LimitError:
 			return result
 		}
 	}
@@ -293,14 +610,14 @@ func evaluateProgram(statements []ast.Statement, environment *object.Environment
 	return result
 }
 
-func evaluateBlockStatement(block *ast.BlockStatement, environment *object.Environment) object.Object {
+func (e *Evaluator) evaluateBlockStatement(block *ast.BlockStatement, environment *object.Environment) object.Object {
 	var result object.Object
 
 	for _, statement := range block.Statements {
-		result = Evaluate(statement, environment)
+		result = e.Evaluate(statement, environment)
 
 		if result != nil {
-			if result.Type() == object.RETURN_VALUE_OBJECT || result.Type() == object.ERROR_OBJECT {
+			if result.Type() == object.RETURN_VALUE_OBJECT || IsError(result) {
 				return result
 			}
 		}
@@ -313,6 +630,10 @@ func evaluateIndexExpression(left object.Object, index object.Object) object.Obj
 	switch {
 	case left.Type() == object.ARRAY_OBJECT && index.Type() == object.INTEGER_OBJECT:
 		return evaluateArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJECT && index.Type() == object.INTEGER_OBJECT:
+		return evaluateStringIndexExpression(left, index)
+	case left.Type() == object.HASH_OBJECT:
+		return evaluateHashIndexExpression(left, index)
 	default:
 		return newError("index opertor not suported : %s", left.Type())
 	}
@@ -321,15 +642,136 @@ func evaluateIndexExpression(left object.Object, index object.Object) object.Obj
 func evaluateArrayIndexExpression(array object.Object, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
 
-	i := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
+	i, ok := normalizeIndex(index.(*object.Integer).Value, arrayObject.Len())
+	if !ok {
+		return NULL
+	}
+	return arrayObject.At(int(i))
+}
+
+func evaluateStringIndexExpression(str object.Object, index object.Object) object.Object {
+	value := str.(*object.String).Value
 
-	if i < 0 || i > max {
+	i, ok := normalizeIndex(index.(*object.Integer).Value, len(value))
+	if !ok {
 		return NULL
 	}
-	return arrayObject.Elements[i]
+	return object.InternString(value[i : i+1])
+}
+
+func evaluateHashIndexExpression(hash object.Object, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key : %s", index.Type())
+	}
+
+	pair, ok := hashObject.Pairs[key.HashKey()]
+	if !ok {
+		return NULL
+	}
+	return pair.Value
+}
+
+func (e *Evaluator) evaluateHashLiteral(node *ast.HashLiteral, environment *object.Environment) object.Object {
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for _, pair := range node.Pairs {
+		key := e.Evaluate(pair.Key, environment)
+		if IsError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key : %s", key.Type())
+		}
+
+		value := e.Evaluate(pair.Value, environment)
+		if IsError(value) {
+			return value
+		}
+
+		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return e.track(&object.Hash{Pairs: pairs})
+}
+
+func normalizeIndex(index int64, length int) (int64, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+
+	if index < 0 || index >= int64(length) {
+		return 0, false
+	}
+	return index, true
+}
+
+func (e *Evaluator) evaluateSliceBound(bound ast.Expression, environment *object.Environment) object.Object {
+	if bound == nil {
+		return nil
+	}
+
+	evaluated := e.Evaluate(bound, environment)
+	if IsError(evaluated) {
+		return evaluated
+	}
+
+	if evaluated.Type() != object.INTEGER_OBJECT {
+		return newError("slice index must be INTEGER, got %s", evaluated.Type())
+	}
+	return evaluated
+}
+
+func evaluateSliceExpression(left object.Object, start object.Object, end object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		from, to := sliceBounds(start, end, left.Len())
+		return left.Slice(int(from), int(to))
+	case *object.String:
+		from, to := sliceBounds(start, end, len(left.Value))
+		return &object.String{Value: left.Value[from:to]}
+	default:
+		return newError("slice operator not supported : %s", left.Type())
+	}
+}
+
+func sliceBounds(start object.Object, end object.Object, length int) (int64, int64) {
+	from := sliceBound(start, 0, length)
+	to := sliceBound(end, int64(length), length)
+
+	if to < from {
+		to = from
+	}
+	return from, to
+}
+
+func sliceBound(bound object.Object, fallback int64, length int) int64 {
+	if bound == nil {
+		return fallback
+	}
+
+	i := bound.(*object.Integer).Value
+	if i < 0 {
+		i += int64(length)
+	}
+
+	if i < 0 {
+		return 0
+	}
+	if i > int64(length) {
+		return int64(length)
+	}
+	return i
 }
 
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
+
+func newLimitError(limit string, format string, a ...interface{}) *object.LimitError {
+	return &object.LimitError{Limit: limit, Message: fmt.Sprintf(format, a...)}
+}
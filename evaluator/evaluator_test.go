@@ -1,10 +1,13 @@
 package evaluator
 
 import (
+	"context"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"strings"
 	"testing"
+	"time"
 )
 
 func testEvaluate(input string) object.Object {
@@ -102,6 +105,36 @@ func testBooleanObject(t *testing.T, o object.Object, expected bool) bool {
 	return true
 }
 
+func TestEvaluateEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" != "a"`, false},
+		{`"a" == "b"`, false},
+		{`let s = "ab"; s == "a" + "b"`, true},
+		{"[1] == [1]", true},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[1, 2] == [1]", false},
+		{"[1] != [2]", true},
+		{`{"a": 1, 2: [3]} == {2: [3], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{"[] == {}", false},
+		{`1 == "1"`, false},
+		{"let f = fn() { 1 }; f == f", true},
+		{"fn() { 1 } == fn() { 1 }", false},
+		{"len == len", true},
+		{"len == push", false},
+		{"1000000 == 999999 + 1", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
 func TestEvaluateBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -184,8 +217,10 @@ func TestErrorHandling(t *testing.T) {
 		{"5; true + false; 5", "unknown operator : BOOLEAN + BOOLEAN"},
 		{"if(10 > 1) { true + false; }", "unknown operator : BOOLEAN + BOOLEAN"},
 		{"if(10 > 1) { if(10 > 1) { return true + false; } return 1;}", "unknown operator : BOOLEAN + BOOLEAN"},
-		{"foobar", "identifier not found : foobar"},
+		{"foobar", "1:1 : identifier not found : foobar"},
 		{`"Hello" - "World"`, "unknown operator : STRING - STRING"},
+		{"1 / 0", "division by zero"},
+		{"let f = fn(x) { 10 / x }; f(0) + 1", "division by zero"},
 	}
 
 	for _, tt := range tests {
@@ -276,6 +311,68 @@ addTwo(5);
 	testIntegerObject(t, testEvaluate(input), 7)
 }
 
+func TestEvaluateResolvedScopes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let counter = fn(x) { fn(y) { fn(z) { x + y + z } } }; counter(1)(2)(3)", 6},
+		{"let x = 1; let f = fn() { let x = 2; let g = fn() { x }; g() }; f() + x", 3},
+		{"let f = fn() { let g = fn() { later }; let later = 5; g() }; f()", 5},
+		{"let x = 10; let f = fn() { let g = fn() { x }; let r = g(); let x = 2; r }; f()", 10},
+		{"let x = 10; let f = fn() { let g = fn() { x }; let x = 2; g() }; f()", 2},
+		{"let f = fn(x) { let x = x * 2; x }; f(4)", 8},
+		{"let f = fn(a, b) { let [c, ...d] = b; a + c + len(d) }; f(1, [2, 3, 4])", 5},
+		{"let f = fn(x) { match (x) { [a, b] => fn() { a + b + x[0] }, _ => fn() { 0 } } }; f([1, 2])()", 4},
+		{"let f = fn(n) { match (n) { 0 => 0, m => m + f(m - 1) } }; f(4)", 10},
+		{"let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } }; let odd = fn(n) { if (n == 0) { false } else { even(n - 1) } }; even(10)", true},
+		{"let f = fn(c) { if (c) { let x = 1; } x }; f(false)", "identifier not found : x"},
+		{"let f = fn() { missing }; 1", 1},
+		{"let f = fn() { missing }; f()", "identifier not found : missing"},
+		{"let f = fn() { g() }; let g = fn() { 7 }; f()", 7},
+		{"let x = push([], 1); y", "1:22 : identifier not found : y"},
+		{"a + fn() { b }() + c", "1:1 : identifier not found : a; 1:20 : identifier not found : c"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errorObject, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("evaluated expected : object.Error, but was actual : %T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errorObject.Message != expected {
+				t.Errorf("errorObject.Message expected : %s, but was actual : %s", expected, errorObject.Message)
+			}
+		}
+	}
+}
+
+func TestUndefinedNamesAreReportedBeforeExecution(t *testing.T) {
+	environment := object.NewEnvironment()
+
+	program := parser.New(lexer.New("let x = 1; y")).ParseProgram()
+	evaluated := Evaluate(program, environment)
+
+	if errorObject, ok := evaluated.(*object.Error); !ok || errorObject.Message != "1:12 : identifier not found : y" {
+		t.Fatalf("evaluated expected : 1:12 : identifier not found : y, but was actual : %+v", evaluated)
+	}
+	if _, ok := environment.Get("x"); ok {
+		t.Errorf("x expected to be undefined after a resolution error")
+	}
+
+	environment.Set("y", &object.Integer{Value: 2})
+	evaluated = Evaluate(program, environment)
+	testIntegerObject(t, evaluated, 2)
+}
+
 func TestEvaluateStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 
@@ -318,6 +415,17 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("a", "b")`, "wrong number of arguments. got=2, want=1"},
 		{`len([1, 2, 3])`, 3},
+		{`first([1, 2, 3])`, 1},
+		{`first([])`, nil},
+		{`first(1)`, "argument to `first` must be ARRAY, got INTEGER"},
+		{`last([1, 2, 3])`, 3},
+		{`last([])`, nil},
+		{`last([1], [2])`, "wrong number of arguments. got=2, want=1"},
+		{`len(rest([1, 2, 3]))`, 2},
+		{`first(rest(rest([1, 2, 3])))`, 3},
+		{`rest([])`, nil},
+		{`let a = [1, 2, 3]; let b = push(rest(a), 4); [len(a), last(a), len(b), last(b)] == [3, 3, 3, 4]`, true},
+		{`let a = [1, 2, 3, 4]; let b = push(a[0:2], 9); [a[2], b[2], len(b)] == [3, 9, 3]`, true},
 	}
 
 	for _, tt := range tests {
@@ -326,6 +434,10 @@ func TestBuiltinFunctions(t *testing.T) {
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
 		case string:
 			errorObject, ok := evaluated.(*object.Error)
 
@@ -352,13 +464,13 @@ func TestEvaluateArrayLiterals(t *testing.T) {
 		t.Fatalf("evaluated expected : object.Array, but was actual : %T", evaluated)
 	}
 
-	if len(result.Elements) != 3 {
-		t.Fatalf("len(result) expected : 3, but was actual : %d", len(result.Elements))
+	if result.Len() != 3 {
+		t.Fatalf("len(result) expected : 3, but was actual : %d", result.Len())
 	}
 
-	testIntegerObject(t, result.Elements[0], 1)
-	testIntegerObject(t, result.Elements[1], 4)
-	testIntegerObject(t, result.Elements[2], 6)
+	testIntegerObject(t, result.At(0), 1)
+	testIntegerObject(t, result.At(1), 4)
+	testIntegerObject(t, result.At(2), 6)
 }
 
 func TestEvaluateArrayIndexExpressions(t *testing.T) {
@@ -374,7 +486,9 @@ func TestEvaluateArrayIndexExpressions(t *testing.T) {
 		{"let myArray = [1, 2, 3]; myArray[1]", 2},
 		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
 		{"[1, 2, 3][3]", nil},
-		{"[1, 2, 3][-1]", nil},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[1, 2, 3][-4]", nil},
 	}
 
 	for _, tt := range tests {
@@ -388,3 +502,614 @@ func TestEvaluateArrayIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestHigherOrderBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, []int64{2, 4, 6}},
+		{`map([], fn(x) { x * 2 })`, []int64{}},
+		{`filter([1, 2, 3, 4], fn(x) { x > 2 })`, []int64{3, 4}},
+		{`reduce([1, 2, 3, 4], 0, fn(acc, x) { acc + x })`, 10},
+		{`reduce([], 5, fn(acc, x) { acc + x })`, 5},
+		{`each([1, 2], fn(x) { x })`, nil},
+		{`each([1, 2], fn(x) { x + true })`, "type mismatch : INTEGER + BOOLEAN"},
+		{`any([1, 2, 3], fn(x) { x > 2 })`, true},
+		{`any([1, 2, 3], fn(x) { x > 3 })`, false},
+		{`all([1, 2, 3], fn(x) { x > 0 })`, true},
+		{`all([1, 2, 3], fn(x) { x > 1 })`, false},
+		{`find([1, 2, 3], fn(x) { x > 1 })`, 2},
+		{`find([1, 2, 3], fn(x) { x > 3 })`, nil},
+		{`sort_by([3, 1, 2], fn(x) { x })`, []int64{1, 2, 3}},
+		{`sort_by([3, 1, 2], fn(x) { -x })`, []int64{3, 2, 1}},
+		{`sort_by(["bb", "a", "ccc"], fn(x) { len(x) })`, []string{"a", "bb", "ccc"}},
+		{`map([1, 2], len)`, "argument to `len` not supported, got INTEGER"},
+		{`map([1, 2], fn(x) { x + true })`, "type mismatch : INTEGER + BOOLEAN"},
+		{`filter([1], fn(x) { foo })`, "identifier not found : foo"},
+		{`reduce([1], 0, fn(x) { x })`, "wrong number of arguments. got=2, want=1"},
+		{`map(1, fn(x) { x })`, "argument to `map` must be ARRAY, got INTEGER"},
+		{`map([1], 1)`, "argument to `map` must be FUNCTION, got INTEGER"},
+		{`sort_by([1, 2], fn(x) { true })`, "sort key must be INTEGER or STRING, got BOOLEAN"},
+		{`len(map([1, 2], fn(x) {}))`, 2},
+		{`first(map([1, 2], fn(x) {}))`, nil},
+		{`filter([1, 2], fn(x) {})`, []int64{}},
+		{`reduce([1, 2], 0, fn(acc, x) {})`, nil},
+		{`each([1, 2], fn(x) {})`, nil},
+		{`any([1, 2], fn(x) {})`, false},
+		{`all([1, 2], fn(x) {})`, false},
+		{`find([1, 2], fn(x) {})`, nil},
+		{`sort_by([1, 2], fn(x) {})`, "sort key must be INTEGER or STRING, got NULL"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
+		case []int64:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("evaluated expected : object.Array, but was actual : %T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if array.Len() != len(expected) {
+				t.Errorf("array.Len() expected : %d, but was actual : %d", len(expected), array.Len())
+				continue
+			}
+			for i, e := range expected {
+				testIntegerObject(t, array.At(i), e)
+			}
+		case []string:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("evaluated expected : object.Array, but was actual : %T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if array.Inspect() != "["+strings.Join(expected, ", ")+"]" {
+				t.Errorf("array expected : %v, but was actual : %s", expected, array.Inspect())
+			}
+		case string:
+			errorObject, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("evaluated expected : object.Error, but was actual : %T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errorObject.Message != expected {
+				t.Errorf("errorObject.Message expected : %s, but was actual : %s", expected, errorObject.Message)
+			}
+		}
+	}
+}
+
+func TestEvaluateStringIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc"[0]`, "a"},
+		{`"abc"[2]`, "c"},
+		{`"abc"[-1]`, "c"},
+		{`"abc"[3]`, nil},
+		{`"abc"[-4]`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestEvaluateSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][:-1]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4][2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4][-2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][3:1]", "[]"},
+		{"[1, 2, 3, 4][-10:10]", "[1, 2, 3, 4]"},
+		{"let i = 1; [1, 2, 3, 4][i:i + 2]", "[2, 3]"},
+		{`"hello"[2:]`, "llo"},
+		{`"hello"[:-1]`, "hell"},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[4:2]`, ""},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("slice of %q expected : %s, but was actual : %+v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestSliceErrorHandling(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2]["a":]`, "slice index must be INTEGER, got STRING"},
+		{`[1, 2][:true]`, "slice index must be INTEGER, got BOOLEAN"},
+		{`5[1:]`, "slice operator not supported : INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(tt.input)
+
+		errorObject, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("evaluated expected : object.Error, but was actual : %T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errorObject.Message != tt.expected {
+			t.Errorf("errorObject.Message expected : %s, but was actual : %s", tt.expected, errorObject.Message)
+		}
+	}
+}
+
+func testStringObject(t *testing.T, o object.Object, expected string) bool {
+	result, ok := o.(*object.String)
+	if !ok {
+		t.Errorf("object expected : object.String, but was actual : %T (%+v)", o, o)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("result.Value expected : %s, but was actual : %s", expected, result.Value)
+		return false
+	}
+	return true
+}
+
+func TestEvaluateDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let [a, b] = [1, 2]; a", 1},
+		{"let [a, b] = [1, 2]; b", 2},
+		{"let [a, b, ...rest] = [1, 2, 3, 4]; rest", "[3, 4]"},
+		{"let [a, ...rest] = [1]; rest", "[]"},
+		{"let [...all] = [1, 2]; all", "[1, 2]"},
+		{"let pair = fn() { [3, 4] }; let [x, y] = pair(); x * y", 12},
+		{"let [a, b] = [1]; a", "not enough values to destructure. got=1, want=2"},
+		{"let [a] = [1, 2]; a", "too many values to destructure. got=2, want=1"},
+		{"let [a, b] = 5; a", "cannot destructure INTEGER with array pattern [a, b]"},
+		{"let [a, b] = [1, foo]; a", "1:18 : identifier not found : foo"},
+		{`let person = {"name": "monkey", "age": 3}; let {name, age} = person; age`, 3},
+		{`let {"first name": first, pair: [a, b]} = {"first name": 1, "pair": [2, 3], "x": 4}; first + a + b`, 6},
+		{`let {name} = {"name": 1, 2: "two"}; name`, 1},
+		{`let {} = {"a": 1}; 5`, 5},
+		{`let {name} = {"age": 3}; name`, "missing key to destructure : name"},
+		{`let {name} = [1]; name`, "cannot destructure ARRAY with hash pattern {name}"},
+		{`let {a: [x, y]} = {"a": [1]}; x`, "not enough values to destructure. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if errorObject, ok := evaluated.(*object.Error); ok {
+				if errorObject.Message != expected {
+					t.Errorf("errorObject.Message expected : %s, but was actual : %s", expected, errorObject.Message)
+				}
+				continue
+			}
+			if evaluated == nil || evaluated.Inspect() != expected {
+				t.Errorf("evaluated expected : %s, but was actual : %+v", expected, evaluated)
+			}
+		}
+	}
+}
+
+func TestEvaluateConstStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"const a = 5; a", 5},
+		{"const [a, b] = [1, 2]; a + b", 3},
+		{"const a = 5; let f = fn() { let a = 10; a }; f() + a", 15},
+		{"const a = 5; let f = fn(a) { a }; f(1)", 1},
+		{"let a = 5; const a = 6; a", 6},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEvaluate(tt.input), tt.expected)
+	}
+}
+
+func TestConstReassignment(t *testing.T) {
+	environment := object.NewEnvironment()
+
+	inputs := []string{"const a = 5;", "let a = 6;"}
+
+	var evaluated object.Object
+	for _, input := range inputs {
+		program := parser.New(lexer.New(input)).ParseProgram()
+		evaluated = Evaluate(program, environment)
+	}
+
+	errorObject, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("evaluated expected : object.Error, but was actual : %T(%+v)", evaluated, evaluated)
+	}
+
+	if errorObject.Message != "cannot reassign constant : a" {
+		t.Errorf("errorObject.Message expected : cannot reassign constant : a, but was actual : %s", errorObject.Message)
+	}
+
+	value, _ := environment.Get("a")
+	testIntegerObject(t, value, 5)
+}
+
+func TestEvaluateMatchExpressions(t *testing.T) {
+	describe := `
+let describe = fn(x) {
+	match (x) {
+		0 => "zero",
+		-1 => "minus one",
+		"a" | "b" => "letter",
+		true => "yes",
+		[] => "empty",
+		[a] => "one " + a,
+		[a, b] if a == b => "pair of same",
+		[a, b] => "pair",
+		[_, [c, _], ...rest] => c,
+		{kind: "circle", radius} => radius,
+		n if n > 100 => "big",
+		_ => "other",
+	}
+};
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`describe(0)`, "zero"},
+		{`describe(-1)`, "minus one"},
+		{`describe("a")`, "letter"},
+		{`describe("b")`, "letter"},
+		{`describe("c")`, object.Error{Message: "type mismatch : STRING > INTEGER"}},
+		{`describe(true)`, "yes"},
+		{`describe(false)`, object.Error{Message: "type mismatch : BOOLEAN > INTEGER"}},
+		{`describe([])`, "empty"},
+		{`describe(["x"])`, "one x"},
+		{`describe([1, 1])`, "pair of same"},
+		{`describe([1, 2])`, "pair"},
+		{`describe([1, ["c", 2], 3, 4])`, "c"},
+		{`describe(101)`, "big"},
+		{`describe(5)`, "other"},
+		{`describe({"kind": "circle", "radius": "wide"})`, "wide"},
+		{`match ({"kind": "square"}) { {kind: "circle", radius} => radius, {kind} => kind }`, "square"},
+		{`match ({"kind": "circle"}) { {kind: "circle", radius} => radius, [] => "array", _ => "none" }`, "none"},
+		{`match (5) { 1 => 1 }`, nil},
+		{`match (5) { x => { let y = x * 2; y } }`, 10},
+		{`let x = 1; match (5) { x => x }; x`, 1},
+		{`let f = fn(x) { match (x) { 1 => { return 10; }, _ => 0 }; 20 }; f(1)`, 10},
+		{`match (5) { x if x + true => 1 }`, object.Error{Message: "type mismatch : INTEGER + BOOLEAN"}},
+		{`match (foo) { _ => 1 }`, object.Error{Message: "18:8 : identifier not found : foo"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(describe + tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			errorObject, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("evaluated expected : object.Error, but was actual : %T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errorObject.Message != expected.Message {
+				t.Errorf("errorObject.Message expected : %s, but was actual : %s", expected.Message, errorObject.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestEvaluateHashLiterals(t *testing.T) {
+	input := `let two = "two";
+{
+	"one": 10 - 9,
+	two: 1 + 1,
+	"thr" + "ee": 6 / 2,
+	4: 4,
+	true: 5,
+	false: 6
+}`
+
+	evaluated := testEvaluate(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("evaluated expected : object.Hash, but was actual : %T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		TRUE.HashKey():                             5,
+		FALSE.HashKey():                            6,
+	}
+
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("len(result.Pairs) expected : %d, but was actual : %d", len(expected), len(result.Pairs))
+	}
+
+	for key, value := range expected {
+		pair, ok := result.Pairs[key]
+		if !ok {
+			t.Errorf("no pair for given key in pairs")
+			continue
+		}
+		testIntegerObject(t, pair.Value, value)
+	}
+}
+
+func TestEvaluateHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`len({"a": 1, "b": 2})`, 2},
+		{`{"name": "monkey"}[fn(x) { x }]`, "unusable as hash key : FUNCTION"},
+		{`{[1]: 1}`, "unusable as hash key : ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluate(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errorObject, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("evaluated expected : object.Error, but was actual : %T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errorObject.Message != expected {
+				t.Errorf("errorObject.Message expected : %s, but was actual : %s", expected, errorObject.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestBuiltinsRegistry(t *testing.T) {
+	builtins := NewBuiltins()
+	builtins.Register("double", func(runtime object.Runtime, args ...object.Object) object.Object {
+		return &object.Integer{Value: args[0].(*object.Integer).Value * 2}
+	})
+
+	program := parser.New(lexer.New("double(len([1, 2]))")).ParseProgram()
+
+	evaluated := New(builtins).Evaluate(program, object.NewEnvironment())
+	testIntegerObject(t, evaluated, 4)
+
+	evaluated = Evaluate(program, object.NewEnvironment())
+	errorObject, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("evaluated expected : object.Error, but was actual : %T(%+v)", evaluated, evaluated)
+	}
+
+	if errorObject.Message != "1:1 : identifier not found : double" {
+		t.Errorf("errorObject.Message expected : 1:1 : identifier not found : double, but was actual : %s", errorObject.Message)
+	}
+}
+
+func TestSubexpressionsAreEvaluatedOnce(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+		calls    int
+	}{
+		{"tick() + tick()", 3, 2},
+		{"tick() == tick()", false, 2},
+		{"let f = fn() { return tick(); }; f()", 1, 1},
+		{"if (tick() > 0) { 10 } else { 20 }", 10, 1},
+		{"-tick()", -1, 1},
+		{"[tick(), tick()][1]", 2, 2},
+		{"match (tick()) { 1 => tick(), _ => 0 }", 2, 2},
+		{"(1 + true) + tick()", "type mismatch : INTEGER + BOOLEAN", 0},
+		{"let f = fn() { missing }; f() + tick()", "identifier not found : missing", 0},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		builtins := NewBuiltins()
+		builtins.RegisterArity("tick", 0, func(runtime object.Runtime, args ...object.Object) object.Object {
+			calls++
+			return &object.Integer{Value: int64(calls)}
+		})
+
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		evaluated := New(builtins).Evaluate(program, object.NewEnvironment())
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errorObject, ok := evaluated.(*object.Error)
+			if !ok || errorObject.Message != expected {
+				t.Errorf("evaluated of %q expected : %s, but was actual : %T(%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		}
+		if calls != tt.calls {
+			t.Errorf("calls of %q expected : %d, but was actual : %d", tt.input, tt.calls, calls)
+		}
+	}
+}
+
+func TestExecutionLimits(t *testing.T) {
+	tests := []struct {
+		input    string
+		limits   Limits
+		limit    string
+		expected string
+	}{
+		{"let f = fn() { 1 + f() }; f()", Limits{MaxDepth: 50}, "depth", "call depth limit exceeded : 50"},
+		{"let f = fn(n) { if (n > 0) { f(n - 1) } }; f(10); 1 + 1 + 1", Limits{MaxSteps: 20}, "steps", "step limit exceeded : 20"},
+		{"map([1, 2, 3], fn(x) { let f = fn() { 1 + f() }; f() })", Limits{MaxDepth: 10}, "depth", "call depth limit exceeded : 10"},
+		{"let f = fn(x) { match (x) { 1 => 1 + f(x) } }; f(1)", Limits{MaxDepth: 10}, "depth", "call depth limit exceeded : 10"},
+		{"let f = fn(n) { 1 + f(n + 1) }; f(0)", Limits{}, "depth", "call depth limit exceeded : 10000"},
+		{
+			"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) + f(n - 1) } }; f(40)",
+			Limits{Timeout: 10 * time.Millisecond},
+			"context",
+			"evaluation stopped : context deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+
+		e := New(nil)
+		e.SetLimits(tt.limits)
+		evaluated := e.EvaluateContext(context.Background(), program, object.NewEnvironment())
+
+		limitError, ok := evaluated.(*object.LimitError)
+		if !ok {
+			t.Errorf("evaluated expected : object.LimitError, but was actual : %T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if limitError.Limit != tt.limit {
+			t.Errorf("limitError.Limit expected : %s, but was actual : %s", tt.limit, limitError.Limit)
+		}
+
+		if limitError.Message != tt.expected {
+			t.Errorf("limitError.Message expected : %s, but was actual : %s", tt.expected, limitError.Message)
+		}
+	}
+}
+
+func TestExecutionLimitsAreResetPerEvaluation(t *testing.T) {
+	program := parser.New(lexer.New("let f = fn(n) { if (n > 0) { f(n - 1) } else { n } }; f(5)")).ParseProgram()
+
+	e := New(nil)
+	e.SetLimits(Limits{MaxSteps: 200, MaxDepth: 10})
+
+	for i := 0; i < 3; i++ {
+		testIntegerObject(t, e.EvaluateContext(context.Background(), program, object.NewEnvironment()), 0)
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let countdown = fn(n) { if (n == 0) { 0 } else { countdown(n - 1) } }; countdown(100000)", 0},
+		{"let sum = fn(n, total) { if (n == 0) { return total; } return sum(n - 1, total + n); }; sum(100000, 0)", 5000050000},
+		{"let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } }; let odd = fn(n) { if (n == 0) { false } else { even(n - 1) } }; odd(100001)", true},
+		{"let last = fn(xs) { match (xs) { [x] => x, [_, ...rest] => last(rest) } }; last([1, 2, 3])", 3},
+		{"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) } }; let g = fn() { f(1, 2) }; g()", "wrong number of arguments. got=2, want=1"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+
+		e := New(nil)
+		e.SetLimits(Limits{MaxDepth: 10})
+		evaluated := e.EvaluateContext(context.Background(), program, object.NewEnvironment())
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			err, ok := evaluated.(*object.Error)
+			if !ok || err.Message != expected {
+				t.Errorf("error expected : %s, but was actual : %+v", expected, evaluated)
+			}
+		}
+	}
+}
+
+func TestEvaluationCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	program := parser.New(lexer.New("1 + 1")).ParseProgram()
+	evaluated := New(nil).EvaluateContext(ctx, program, object.NewEnvironment())
+
+	limitError, ok := evaluated.(*object.LimitError)
+	if !ok {
+		t.Fatalf("evaluated expected : object.LimitError, but was actual : %T(%+v)", evaluated, evaluated)
+	}
+
+	if limitError.Message != "evaluation stopped : context canceled" {
+		t.Errorf("limitError.Message expected : evaluation stopped : context canceled, but was actual : %s", limitError.Message)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		input     string
+		maxMemory int64
+		exceeded  bool
+	}{
+		{"let grow = fn(arr, n) { if (n == 0) { arr } else { grow(push(arr, n), n - 1) } }; len(grow([], 1000))", 10000, true},
+		{"let grow = fn(arr, n) { if (n == 0) { arr } else { grow(push(arr, n), n - 1) } }; len(grow([], 10))", 10000, false},
+		{`let grow = fn(s, n) { if (n == 0) { s } else { grow(s + s, n - 1) } }; len(grow("ab", 20))`, 100000, true},
+		{"let deep = fn(n) { if (n == 0) { 0 } else { deep(n - 1) } }; deep(500)", 10000, true},
+		{"map([1, 2, 3], fn(x) { [x, x, x, x] })", 200, true},
+		{"[1, 2, 3][1:]", 0, false},
+		{`let loop = fn(n) { if (n == 0) { 0 } else { let s = "constant"; loop(n - 1) } }; loop(100)`, 9000, false},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+
+		e := New(nil)
+		e.SetLimits(Limits{MaxMemory: tt.maxMemory})
+		evaluated := e.EvaluateContext(context.Background(), program, object.NewEnvironment())
+
+		limitError, ok := evaluated.(*object.LimitError)
+		if ok != tt.exceeded {
+			t.Errorf("%q exceeded expected : %t, but was actual : %T(%+v)", tt.input, tt.exceeded, evaluated, evaluated)
+			continue
+		}
+
+		if ok && limitError.Limit != "memory" {
+			t.Errorf("limitError.Limit expected : memory, but was actual : %s", limitError.Limit)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"context"
+	"monkey/ast"
+	"monkey/object"
+	"time"
+)
+
+const DEFAULT_MAX_DEPTH = 10000
+
+type Limits struct {
+	MaxSteps  int
+	MaxDepth  int
+	MaxMemory int64
+	Timeout   time.Duration
+}
+
+func (e *Evaluator) SetLimits(limits Limits) {
+	e.limits = limits
+}
+
+func (e *Evaluator) EvaluateContext(ctx context.Context, node ast.Node, environment *object.Environment) object.Object {
+	if e.running {
+		return e.Evaluate(node, environment)
+	}
+
+	cancel := e.start(ctx)
+	defer cancel()
+
+	return e.finish(e.Evaluate(node, environment))
+}
+
+func (e *Evaluator) ApplyContext(ctx context.Context, function object.Object, args ...object.Object) object.Object {
+	if e.running {
+		return e.Apply(function, args...)
+	}
+
+	cancel := e.start(ctx)
+	defer cancel()
+
+	return e.finish(e.Apply(function, args...))
+}
+
+func (e *Evaluator) start(ctx context.Context) context.CancelFunc {
+	var cancel context.CancelFunc = func() {}
+	if e.limits.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.limits.Timeout)
+	}
+
+	e.ctx = ctx
+	e.steps = 0
+	e.depth = 0
+	e.allocated = 0
+	e.exceeded = nil
+	e.running = true
+
+	return func() {
+		cancel()
+		e.running = false
+	}
+}
+
+func (e *Evaluator) finish(result object.Object) object.Object {
+	if e.exceeded != nil {
+		return e.exceeded
+	}
+	return result
+}
+
+func (e *Evaluator) step() object.Object {
+	if e.exceeded != nil {
+		return e.exceeded
+	}
+
+	e.steps++
+	if e.limits.MaxSteps > 0 && e.steps > e.limits.MaxSteps {
+		return e.exceed("steps", "step limit exceeded : %d", e.limits.MaxSteps)
+	}
+
+	if e.ctx == nil {
+		return nil
+	}
+
+	select {
+	case <-e.ctx.Done():
+		return e.exceed("context", "evaluation stopped : %s", e.ctx.Err())
+	default:
+		return nil
+	}
+}
+
+func (e *Evaluator) exceed(limit string, format string, a ...interface{}) object.Object {
+	e.exceeded = newLimitError(limit, format, a...)
+	return e.exceeded
+}
+
+func (e *Evaluator) enter() object.Object {
+	maxDepth := e.limits.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DEFAULT_MAX_DEPTH
+	}
+
+	e.depth++
+	if maxDepth > 0 && e.depth > maxDepth {
+		return e.exceed("depth", "call depth limit exceeded : %d", maxDepth)
+	}
+	return nil
+}
+
+func (e *Evaluator) leave() {
+	e.depth--
+}
@@ -0,0 +1,73 @@
+package evaluator
+
+import "monkey/object"
+
+const (
+	objectSize      = 16
+	environmentSize = 64
+)
+
+func stringSize(length int) int64 {
+	return objectSize + int64(length)
+}
+
+func arraySize(length int) int64 {
+	return objectSize + objectSize*int64(length)
+}
+
+func hashSize(length int) int64 {
+	return objectSize + 3*objectSize*int64(length)
+}
+
+func environmentSizeOf(bindings int) int64 {
+	return environmentSize + objectSize*int64(bindings)
+}
+
+func sizeOf(o object.Object) int64 {
+	switch o := o.(type) {
+	case *object.String:
+		return stringSize(len(o.Value))
+	case *object.Array:
+		return arraySize(o.Len())
+	case *object.Hash:
+		return hashSize(len(o.Pairs))
+	default:
+		return objectSize
+	}
+}
+
+func SizeOf(o object.Object) int64 {
+	size := sizeOf(o)
+
+	switch o := o.(type) {
+	case *object.Array:
+		for _, e := range o.Elements() {
+			size += SizeOf(e)
+		}
+	case *object.Hash:
+		for _, pair := range o.Pairs {
+			size += SizeOf(pair.Key) + SizeOf(pair.Value)
+		}
+	}
+
+	return size
+}
+
+func (e *Evaluator) Allocate(size int64) object.Object {
+	e.allocated += size
+	if e.limits.MaxMemory > 0 && e.allocated > e.limits.MaxMemory {
+		return e.exceed("memory", "memory limit exceeded : %d bytes", e.limits.MaxMemory)
+	}
+	return nil
+}
+
+func (e *Evaluator) track(o object.Object) object.Object {
+	if IsError(o) {
+		return o
+	}
+
+	if err := e.Allocate(sizeOf(o)); err != nil {
+		return err
+	}
+	return o
+}
@@ -0,0 +1,18 @@
+package evaluator
+
+import "monkey/object"
+
+const TAIL_CALL_OBJECT = "TAIL_CALL"
+
+type tailCall struct {
+	function *object.Function
+	args     []object.Object
+}
+
+func (t *tailCall) Type() object.Type {
+	return TAIL_CALL_OBJECT
+}
+
+func (t *tailCall) Inspect() string {
+	return "tail call"
+}
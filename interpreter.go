@@ -0,0 +1,180 @@
+package monkey
+
+import (
+	"context"
+	"fmt"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/optimizer"
+	"monkey/parser"
+	"strings"
+	"time"
+)
+
+type Options struct {
+	Globals  map[string]interface{}
+	Builtins map[string]interface{}
+
+	MaxSteps  int
+	MaxDepth  int
+	MaxMemory int64
+	Timeout   time.Duration
+
+	Optimize bool
+}
+
+type Interpreter struct {
+	environment *object.Environment
+	builtins    evaluator.Builtins
+	evaluator   *evaluator.Evaluator
+	optimize    bool
+}
+
+type ParseError struct {
+	Messages []string
+}
+
+func (e *ParseError) Error() string {
+	return "parse error : " + strings.Join(e.Messages, "; ")
+}
+
+type LimitError struct {
+	Limit   string
+	Message string
+}
+
+func (e *LimitError) Error() string {
+	return e.Message
+}
+
+type RuntimeError struct {
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Message
+}
+
+func NewInterpreter(opts Options) (*Interpreter, error) {
+	builtins := evaluator.NewBuiltins()
+	interpreter := &Interpreter{
+		environment: object.NewEnvironment(),
+		builtins:    builtins,
+		evaluator:   evaluator.New(builtins),
+		optimize:    opts.Optimize,
+	}
+
+	interpreter.evaluator.SetLimits(evaluator.Limits{
+		MaxSteps:  opts.MaxSteps,
+		MaxDepth:  opts.MaxDepth,
+		MaxMemory: opts.MaxMemory,
+		Timeout:   opts.Timeout,
+	})
+
+	for name, function := range opts.Builtins {
+		if err := interpreter.Register(name, function); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, value := range opts.Globals {
+		if err := interpreter.Define(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return interpreter, nil
+}
+
+func (i *Interpreter) Eval(ctx context.Context, src string) (value interface{}, err error) {
+	defer recoverPanic(&err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Messages: p.Errors()}
+	}
+
+	if i.optimize {
+		program = optimizer.Optimize(program)
+	}
+
+	return i.result(i.evaluator.EvaluateContext(ctx, program, i.environment))
+}
+
+func (i *Interpreter) Define(name string, value interface{}) error {
+	o, err := ToObject(value)
+	if err != nil {
+		return err
+	}
+
+	if i.environment.IsConstant(name) {
+		return fmt.Errorf("cannot reassign constant : %s", name)
+	}
+
+	i.environment.Set(name, o)
+	return nil
+}
+
+func (i *Interpreter) Register(name string, function interface{}) error {
+	builtin, err := builtinFunction(function)
+	if err != nil {
+		return err
+	}
+
+	i.builtins[name] = builtin
+	return nil
+}
+
+func (i *Interpreter) Builtins() evaluator.Builtins {
+	builtins := make(evaluator.Builtins, len(i.builtins))
+	for name, builtin := range i.builtins {
+		builtins[name] = builtin
+	}
+	return builtins
+}
+
+func (i *Interpreter) Call(name string, args ...interface{}) (value interface{}, err error) {
+	defer recoverPanic(&err)
+
+	function := i.evaluator.EvaluateContext(context.Background(), &ast.Identifier{Value: name}, i.environment)
+	if evaluator.IsError(function) {
+		return i.result(function)
+	}
+
+	arguments := make([]object.Object, len(args))
+	for index, arg := range args {
+		o, err := ToObject(arg)
+		if err != nil {
+			return nil, err
+		}
+		arguments[index] = o
+	}
+
+	return i.result(i.evaluator.ApplyContext(context.Background(), function, arguments...))
+}
+
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &RuntimeError{Message: fmt.Sprintf("runtime panic : %v", r)}
+	}
+}
+
+func (i *Interpreter) result(evaluated object.Object) (interface{}, error) {
+	return result(evaluated, i.evaluator)
+}
+
+func result(evaluated object.Object, runtime object.Runtime) (interface{}, error) {
+	if evaluated == nil {
+		return nil, nil
+	}
+
+	return toGo(evaluated, runtime)
+}
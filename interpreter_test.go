@@ -0,0 +1,581 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monkey/object"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestInterpreter(t *testing.T, opts Options) *Interpreter {
+	interpreter, err := NewInterpreter(opts)
+	if err != nil {
+		t.Fatalf("NewInterpreter returned error : %s", err)
+	}
+	return interpreter
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1 + 2", int64(3)},
+		{`"a" + "b"`, "ab"},
+		{"1 < 2", true},
+		{"if (false) { 1 }", nil},
+		{"[1, [2, 3]]", []interface{}{int64(1), []interface{}{int64(2), int64(3)}}},
+		{`{"a": 1, 2: true}`, map[interface{}]interface{}{"a": int64(1), int64(2): true}},
+		{"let a = 1;", nil},
+	}
+
+	for _, tt := range tests {
+		interpreter := newTestInterpreter(t, Options{})
+
+		actual, err := interpreter.Eval(context.Background(), tt.input)
+		if err != nil {
+			t.Errorf("Eval(%q) returned error : %s", tt.input, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Eval(%q) expected : %#v, but was actual : %#v", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestEvalKeepsEnvironment(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+	ctx := context.Background()
+
+	if _, err := interpreter.Eval(ctx, "let double = fn(x) { x * 2 };"); err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+
+	actual, err := interpreter.Eval(ctx, "double(21)")
+	if err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+
+	if actual != int64(42) {
+		t.Errorf("actual expected : 42, but was actual : %v", actual)
+	}
+}
+
+func TestEvalOptimized(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{Optimize: true, MaxSteps: 3})
+	ctx := context.Background()
+
+	actual, err := interpreter.Eval(ctx, "60 * 60 * 24 * 7")
+	if err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+	if actual != int64(604800) {
+		t.Errorf("actual expected : 604800, but was actual : %v", actual)
+	}
+
+	interpreter = newTestInterpreter(t, Options{Optimize: true})
+	if _, err := interpreter.Eval(ctx, "let rate = 2; let scale = fn(x) { x * rate };"); err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+
+	actual, err = interpreter.Eval(ctx, "let rate = 3; scale(2)")
+	if err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+	if actual != int64(6) {
+		t.Errorf("actual expected : 6, but was actual : %v", actual)
+	}
+}
+
+func TestEvalDoesNotPanic(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{
+		Builtins: map[string]interface{}{
+			"boom": func(runtime object.Runtime, args ...object.Object) object.Object { panic("boom") },
+		},
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 / 0", "division by zero"},
+		{"let half = fn(x) { x / 2 }; half(1) / half(1)", "division by zero"},
+		{"boom()", "runtime panic : boom"},
+	}
+
+	for _, tt := range tests {
+		_, err := interpreter.Eval(context.Background(), tt.input)
+
+		var runtimeError *RuntimeError
+		if !errors.As(err, &runtimeError) {
+			t.Errorf("Eval(%q) error expected : *RuntimeError, but was actual : %T (%v)", tt.input, err, err)
+			continue
+		}
+		if runtimeError.Message != tt.expected {
+			t.Errorf("Eval(%q) error expected : %s, but was actual : %s", tt.input, tt.expected, runtimeError.Message)
+		}
+	}
+
+	if _, err := interpreter.Call("boom"); err == nil || err.Error() != "runtime panic : boom" {
+		t.Errorf("Call(boom) error expected : runtime panic : boom, but was actual : %v", err)
+	}
+
+	if actual, err := interpreter.Eval(context.Background(), "6 / 3"); err != nil || actual != int64(2) {
+		t.Errorf("Eval after a panic expected : 2, but was actual : %v (%v)", actual, err)
+	}
+}
+
+func TestEvalLateBinding(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+	ctx := context.Background()
+
+	if _, err := interpreter.Eval(ctx, "let f = fn() { g() };"); err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+
+	if _, err := interpreter.Eval(ctx, "f()"); err == nil || err.Error() != "identifier not found : g" {
+		t.Errorf("f() error expected : identifier not found : g, but was actual : %v", err)
+	}
+
+	actual, err := interpreter.Eval(ctx, "let g = fn() { 42 }; f()")
+	if err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+
+	if actual != int64(42) {
+		t.Errorf("actual expected : 42, but was actual : %v", actual)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+
+	_, err := interpreter.Eval(context.Background(), "let = 5;")
+	var parseError *ParseError
+	if !errors.As(err, &parseError) {
+		t.Errorf("err expected : *ParseError, but was actual : %T (%v)", err, err)
+	}
+
+	_, err = interpreter.Eval(context.Background(), "1 + true")
+	var runtimeError *RuntimeError
+	if !errors.As(err, &runtimeError) {
+		t.Fatalf("err expected : *RuntimeError, but was actual : %T (%v)", err, err)
+	}
+	if runtimeError.Message != "type mismatch : INTEGER + BOOLEAN" {
+		t.Errorf("runtimeError.Message expected : type mismatch : INTEGER + BOOLEAN, but was actual : %s", runtimeError.Message)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := interpreter.Eval(ctx, "1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err expected : context.Canceled, but was actual : %v", err)
+	}
+}
+
+func TestDefine(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		input    string
+		expected interface{}
+	}{
+		{42, "x + 1", int64(43)},
+		{uint8(7), "x", int64(7)},
+		{"go", `x + "!"`, "go!"},
+		{true, "!x", false},
+		{nil, "x", nil},
+		{[]int{1, 2, 3}, "len(x)", int64(3)},
+		{[2]string{"a", "b"}, "x[1]", "b"},
+		{map[string]int{"a": 1}, `x["a"]`, int64(1)},
+		{map[int][]string{1: {"a"}}, `x[1][0]`, "a"},
+		{func(a, b int) int { return a + b }, "x(1, 2)", int64(3)},
+		{func(s string) string { return strings.ToUpper(s) }, `x("go")`, "GO"},
+		{func(xs []int) int { return len(xs) }, "x([1, 2])", int64(2)},
+		{func(m map[string]bool) bool { return m["a"] }, `x({"a": true})`, true},
+		{func(values ...interface{}) int { return len(values) }, `x(1, "a", [])`, int64(3)},
+		{func(prefix string, values ...int) string { return fmt.Sprint(prefix, values) }, `x("p", 1, 2)`, "p[1 2]"},
+		{func() {}, "x()", nil},
+		{func(f Function) interface{} { r, _ := f(20); return r }, "x(fn(n) { n + 1 })", int64(21)},
+	}
+
+	for _, tt := range tests {
+		interpreter := newTestInterpreter(t, Options{})
+
+		if err := interpreter.Define("x", tt.value); err != nil {
+			t.Errorf("Define(%#v) returned error : %s", tt.value, err)
+			continue
+		}
+
+		actual, err := interpreter.Eval(context.Background(), tt.input)
+		if err != nil {
+			t.Errorf("Eval(%q) returned error : %s", tt.input, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Eval(%q) expected : %#v, but was actual : %#v", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestDefineErrors(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+
+	if err := interpreter.Define("x", 1.5); err == nil {
+		t.Errorf("Define(1.5) expected error")
+	}
+
+	if err := interpreter.Define("x", uint64(1<<63)); err == nil {
+		t.Errorf("Define(1<<63) expected error")
+	}
+
+	if err := interpreter.Define("x", func() (int, int) { return 1, 2 }); err == nil {
+		t.Errorf("Define(func() (int, int)) expected error")
+	}
+
+	if _, err := interpreter.Eval(context.Background(), "const c = 1;"); err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+	if err := interpreter.Define("c", 2); err == nil {
+		t.Errorf("Define(c) expected error")
+	}
+}
+
+func TestGoFunctionArgumentErrors(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{
+		Globals: map[string]interface{}{
+			"add":   func(a, b int8) int8 { return a + b },
+			"upper": func(s string) string { return strings.ToUpper(s) },
+		},
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"add(1)", "wrong number of arguments. got=1, want=2"},
+		{"add(1, 1000)", "integer overflow : 1000 does not fit in int8"},
+		{"upper(1)", "cannot convert INTEGER to string"},
+	}
+
+	for _, tt := range tests {
+		_, err := interpreter.Eval(context.Background(), tt.input)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("Eval(%q) error expected : %s, but was actual : %v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+
+	_, err := interpreter.Eval(context.Background(), `
+let greet = fn(name, times) { len(name) * times };
+let first = fn(xs) { xs[0] };
+`)
+	if err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+
+	actual, err := interpreter.Call("greet", "monkey", 2)
+	if err != nil {
+		t.Fatalf("Call returned error : %s", err)
+	}
+	if actual != int64(12) {
+		t.Errorf("Call expected : 12, but was actual : %v", actual)
+	}
+
+	actual, err = interpreter.Call("first", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Call returned error : %s", err)
+	}
+	if actual != "a" {
+		t.Errorf("Call expected : a, but was actual : %v", actual)
+	}
+
+	actual, err = interpreter.Call("len", "four")
+	if err != nil {
+		t.Fatalf("Call returned error : %s", err)
+	}
+	if actual != int64(4) {
+		t.Errorf("Call expected : 4, but was actual : %v", actual)
+	}
+
+	if _, err := interpreter.Call("missing"); err == nil || err.Error() != "identifier not found : missing" {
+		t.Errorf("Call(missing) error expected : identifier not found : missing, but was actual : %v", err)
+	}
+
+	if _, err := interpreter.Call("100%d"); err == nil || err.Error() != "identifier not found : 100%d" {
+		t.Errorf("Call(100%%d) error expected : identifier not found : 100%%d, but was actual : %v", err)
+	}
+
+	if _, err := interpreter.Call("greet", "a"); err == nil {
+		t.Errorf("Call(greet) expected error")
+	}
+}
+
+func TestCallbackFunction(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+
+	value, err := interpreter.Eval(context.Background(), "fn(a, b) { a + b }")
+	if err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+
+	function, ok := value.(Function)
+	if !ok {
+		t.Fatalf("value expected : Function, but was actual : %T", value)
+	}
+
+	actual, err := function(1, 2)
+	if err != nil {
+		t.Fatalf("function returned error : %s", err)
+	}
+	if actual != int64(3) {
+		t.Errorf("function expected : 3, but was actual : %v", actual)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{
+		Builtins: map[string]interface{}{
+			"div": func(a, b int) (int, error) {
+				if b == 0 {
+					return 0, errors.New("division by zero")
+				}
+				return a / b, nil
+			},
+		},
+	})
+
+	registered := []struct {
+		name     string
+		function interface{}
+	}{
+		{"check", func(ok bool) error {
+			if !ok {
+				return errors.New("check failed")
+			}
+			return nil
+		}},
+		{"apply", func(f Function, x int) (interface{}, error) { return f(x) }},
+		{"raw", func(runtime object.Runtime, args ...object.Object) object.Object {
+			return runtime.Apply(args[0], args[1])
+		}},
+		{"add", func(a, b int) int { return a + b }},
+		{"sub", func(a, b int) int { return a - b }},
+	}
+
+	for _, r := range registered {
+		if err := interpreter.Register(r.name, r.function); err != nil {
+			t.Fatalf("Register(%s) returned error : %s", r.name, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+		err      string
+	}{
+		{"div(10, 2)", int64(5), ""},
+		{"div(1, 0)", nil, "division by zero"},
+		{"let f = fn() { div(1, 0); 5 }; f()", nil, "division by zero"},
+		{"check(true)", nil, ""},
+		{"check(false)", nil, "check failed"},
+		{"apply(fn(x) { len([x, x]) * x }, 3)", int64(6), ""},
+		{"apply(fn(x) { x + true }, 3)", nil, "type mismatch : INTEGER + BOOLEAN"},
+		{"raw(fn(x) { x * 10 }, 4)", int64(40), ""},
+		{"add == sub", false, ""},
+		{"add != sub", true, ""},
+		{"add == add", true, ""},
+		{"let plus = add; plus == add", true, ""},
+		{"let div = fn(a, b) { a - b }; div(10, 2)", int64(8), ""},
+	}
+
+	for _, tt := range tests {
+		actual, err := interpreter.Eval(context.Background(), tt.input)
+
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Eval(%q) error expected : %s, but was actual : %v", tt.input, tt.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Eval(%q) returned error : %s", tt.input, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Eval(%q) expected : %#v, but was actual : %#v", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestRegisterIsPerInterpreter(t *testing.T) {
+	first := newTestInterpreter(t, Options{})
+	second := newTestInterpreter(t, Options{})
+
+	if err := first.Register("answer", func() int { return 42 }); err != nil {
+		t.Fatalf("Register returned error : %s", err)
+	}
+
+	if actual, err := first.Eval(context.Background(), "answer()"); err != nil || actual != int64(42) {
+		t.Errorf("first.Eval expected : 42, but was actual : %v (%v)", actual, err)
+	}
+
+	if _, err := second.Eval(context.Background(), "answer()"); err == nil || err.Error() != "1:1 : identifier not found : answer" {
+		t.Errorf("second.Eval error expected : 1:1 : identifier not found : answer, but was actual : %v", err)
+	}
+}
+
+func TestRegisterArity(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+
+	tests := []struct {
+		name     string
+		function interface{}
+		arity    int
+		hasArity bool
+	}{
+		{"none", func() int { return 1 }, 0, true},
+		{"pair", func(a int, b string) {}, 2, true},
+		{"variadic", func(values ...int) {}, 1, false},
+		{"raw", func(runtime object.Runtime, args ...object.Object) object.Object { return nil }, 0, false},
+		{"host", &object.Builtin{Function: func(runtime object.Runtime, args ...object.Object) object.Object { return nil }}, 0, false},
+	}
+
+	for _, tt := range tests {
+		if err := interpreter.Register(tt.name, tt.function); err != nil {
+			t.Fatalf("Register(%s) returned error : %s", tt.name, err)
+		}
+
+		builtin := interpreter.Builtins()[tt.name]
+		if builtin.Arity != tt.arity || builtin.HasArity != tt.hasArity {
+			t.Errorf("arity of %s expected : %d (%t), but was actual : %d (%t)", tt.name, tt.arity, tt.hasArity, builtin.Arity, builtin.HasArity)
+		}
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+
+	if err := interpreter.Register("x", 1); err == nil {
+		t.Errorf("Register(1) expected error")
+	}
+
+	if err := interpreter.Register("x", func() (int, int, error) { return 1, 2, nil }); err == nil {
+		t.Errorf("Register(func() (int, int, error)) expected error")
+	}
+
+	if _, err := NewInterpreter(Options{Builtins: map[string]interface{}{"x": "y"}}); err == nil {
+		t.Errorf("NewInterpreter expected error")
+	}
+}
+
+func TestExecutionLimits(t *testing.T) {
+	tests := []struct {
+		opts  Options
+		input string
+		limit string
+	}{
+		{Options{MaxDepth: 100}, "let f = fn() { 1 + f() }; f()", "depth"},
+		{Options{MaxSteps: 1000}, "let f = fn(n) { f(n + 1) }; f(0)", "steps"},
+		{Options{Timeout: 10 * time.Millisecond}, "let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) + f(n - 1) } }; f(40)", "context"},
+		{
+			Options{MaxDepth: 10, Builtins: map[string]interface{}{"call": func(f Function) (interface{}, error) { return f() }}},
+			"let f = fn() { call(f) }; f()",
+			"depth",
+		},
+	}
+
+	for _, tt := range tests {
+		interpreter := newTestInterpreter(t, tt.opts)
+
+		_, err := interpreter.Eval(context.Background(), tt.input)
+
+		var limitError *LimitError
+		if !errors.As(err, &limitError) {
+			t.Errorf("Eval(%q) error expected : *LimitError, but was actual : %T (%v)", tt.input, err, err)
+			continue
+		}
+
+		if limitError.Limit != tt.limit {
+			t.Errorf("limitError.Limit expected : %s, but was actual : %s", tt.limit, limitError.Limit)
+		}
+	}
+}
+
+func TestCallbackAfterLimitedEval(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{Timeout: time.Second, MaxSteps: 10000})
+	ctx := context.Background()
+
+	value, err := interpreter.Eval(ctx, "fn(x) { x * 2 }")
+	if err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+	double := value.(Function)
+
+	if actual, err := double(21); err != nil || actual != int64(42) {
+		t.Errorf("double(21) expected : 42, but was actual : %v (%v)", actual, err)
+	}
+
+	if _, err := interpreter.Eval(ctx, "let f = fn(n) { f(n + 1) }; f(0)"); err == nil {
+		t.Fatalf("Eval expected a step limit error")
+	}
+
+	if actual, err := double(4); err != nil || actual != int64(8) {
+		t.Errorf("double(4) after a limit error expected : 8, but was actual : %v (%v)", actual, err)
+	}
+
+	if actual, err := interpreter.Call("f", 1); err == nil || err.Error() != "step limit exceeded : 10000" {
+		t.Errorf("Call(f) expected : step limit exceeded : 10000, but was actual : %v (%v)", actual, err)
+	}
+}
+
+func TestEvalContextDeadline(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := interpreter.Eval(ctx, "let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) + f(n - 1) } }; f(40)")
+
+	var limitError *LimitError
+	if !errors.As(err, &limitError) {
+		t.Fatalf("err expected : *LimitError, but was actual : %T (%v)", err, err)
+	}
+
+	if limitError.Message != "evaluation stopped : context deadline exceeded" {
+		t.Errorf("limitError.Message expected : evaluation stopped : context deadline exceeded, but was actual : %s", limitError.Message)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	interpreter := newTestInterpreter(t, Options{
+		MaxMemory: 100000,
+		Builtins: map[string]interface{}{
+			"zeros": func(n int) []int { return make([]int, n) },
+		},
+	})
+
+	if _, err := interpreter.Eval(context.Background(), "len(zeros(10))"); err != nil {
+		t.Fatalf("Eval returned error : %s", err)
+	}
+
+	_, err := interpreter.Eval(context.Background(), "len(zeros(100000))")
+
+	var limitError *LimitError
+	if !errors.As(err, &limitError) {
+		t.Fatalf("err expected : *LimitError, but was actual : %T (%v)", err, err)
+	}
+
+	if limitError.Message != "memory limit exceeded : 100000 bytes" {
+		t.Errorf("limitError.Message expected : memory limit exceeded : 100000 bytes, but was actual : %s", limitError.Message)
+	}
+}
@@ -1,24 +1,49 @@
 package lexer
 
-import "monkey/token"
+import (
+	"io"
+	"monkey/token"
+)
+
+const BUFFER_SIZE = 4096
 
 type Lexer struct {
-	input   string
+	input   []byte
+	base    int
+	start   int
+	reader  io.Reader
+	done    bool
+	err     error
 	current int
 	peek    int
 	char    byte
+	line    int
+	column  int
 }
 
 func New(input string) *Lexer {
-	lexer := &Lexer{input: input}
+	lexer := &Lexer{input: []byte(input), line: 1, done: true}
+	lexer.readChar()
+	return lexer
+}
+
+func NewReader(reader io.Reader) *Lexer {
+	lexer := &Lexer{input: make([]byte, 0, BUFFER_SIZE), reader: reader, line: 1}
 	lexer.readChar()
 	return lexer
 }
 
+func (l *Lexer) Err() error {
+	return l.err
+}
+
 func (l *Lexer) NextToken() token.Token {
 	var searched token.Token
 
 	l.skipWhitespace()
+	l.start = l.current
+
+	position := token.Position{Offset: l.current, Line: l.line, Column: l.column}
 
 	switch l.char {
 	case '[':
@@ -31,6 +56,11 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(ch) + string(l.char)
 			searched = token.New(token.EQUAL, literal)
+		} else if l.peekChar() == '>' {
+			ch := l.char
+			l.readChar()
+			literal := string(ch) + string(l.char)
+			searched = token.New(token.ARROW, literal)
 		} else {
 			searched = token.New(token.ASSIGN, string(l.char))
 		}
@@ -46,6 +76,18 @@ func (l *Lexer) NextToken() token.Token {
 		searched = token.New(token.RBRACE, string(l.char))
 	case ',':
 		searched = token.New(token.COMMA, string(l.char))
+	case '|':
+		searched = token.New(token.PIPE, string(l.char))
+	case ':':
+		searched = token.New(token.COLON, string(l.char))
+	case '.':
+		if l.peekChar() == '.' && l.peekCharAt(1) == '.' {
+			l.readChar()
+			l.readChar()
+			searched = token.New(token.ELLIPSIS, "...")
+		} else {
+			searched = token.New(token.ILLEGAL, string(l.char))
+		}
 	case '+':
 		searched = token.New(token.PLUS, string(l.char))
 	case '-':
@@ -68,16 +110,21 @@ func (l *Lexer) NextToken() token.Token {
 	case '>':
 		searched = token.New(token.GREATER, string(l.char))
 	case '"':
-		searched = token.New(token.STRING, l.readString())
+		s, terminated := l.readString()
+		if terminated {
+			searched = token.New(token.STRING, s)
+		} else {
+			searched = token.New(token.ILLEGAL, `"`+s)
+		}
 	case 0:
 		searched = token.New(token.EOF, "")
 	default:
 		if isLetter(l.char) {
 
 			s := l.readIdentifier()
-			return token.New(token.FindStringType(s), s)
+			return token.At(token.FindStringType(s), s, position)
 		} else if isDigit(l.char) {
-			return token.New(token.NUMBER, l.readNumber())
+			return token.At(token.NUMBER, l.readNumber(), position)
 		} else {
 			searched = token.New(token.ILLEGAL, string(l.char))
 		}
@@ -85,20 +132,59 @@ func (l *Lexer) NextToken() token.Token {
 
 	l.readChar()
 
+	searched.Position = position
 	return searched
 }
 
 func (l *Lexer) readChar() {
-	if l.peek >= len(l.input) {
-		l.char = 0
+	if l.char == '\n' {
+		l.line++
+		l.column = 1
 	} else {
-		l.char = l.input[l.peek]
+		l.column++
 	}
+
+	l.char = l.byteAt(l.peek)
 	l.current = l.peek
 	l.peek++
 }
 
-func (l *Lexer) readString() string {
+func (l *Lexer) byteAt(offset int) byte {
+	for offset-l.base >= len(l.input) {
+		if l.done {
+			return 0
+		}
+		l.fill()
+	}
+	return l.input[offset-l.base]
+}
+
+func (l *Lexer) fill() {
+	if len(l.input) == cap(l.input) {
+		kept := l.input[l.start-l.base:]
+		input := l.input
+		if len(kept) > cap(l.input)/2 {
+			input = make([]byte, 0, 2*cap(l.input))
+		}
+		l.input = append(input[:0], kept...)
+		l.base = l.start
+	}
+
+	n, err := l.reader.Read(l.input[len(l.input):cap(l.input)])
+	l.input = l.input[:len(l.input)+n]
+	if err != nil {
+		if err != io.EOF {
+			l.err = err
+		}
+		l.done = true
+	}
+}
+
+func (l *Lexer) slice(start int) string {
+	return string(l.input[start-l.base : l.current-l.base])
+}
+
+func (l *Lexer) readString() (string, bool) {
 	start := l.current + 1
 	for {
 		l.readChar()
@@ -106,7 +192,7 @@ func (l *Lexer) readString() string {
 			break
 		}
 	}
-	return l.input[start:l.current]
+	return l.slice(start), l.char == '"'
 }
 
 func (l *Lexer) readIdentifier() string {
@@ -115,7 +201,7 @@ func (l *Lexer) readIdentifier() string {
 		l.readChar()
 	}
 
-	return l.input[start:l.current]
+	return l.slice(start)
 }
 
 func (l *Lexer) readNumber() string {
@@ -123,21 +209,22 @@ func (l *Lexer) readNumber() string {
 	for isDigit(l.char) {
 		l.readChar()
 	}
-	return l.input[start:l.current]
+	return l.slice(start)
 }
 
 func (l *Lexer) skipWhitespace() {
 	for l.char == ' ' || l.char == '\t' || l.char == '\n' || l.char == '\r' {
+		l.start = l.current
 		l.readChar()
 	}
 }
 
 func (l *Lexer) peekChar() byte {
-	if l.peek >= len(l.input) {
-		return 0
-	}
+	return l.peekCharAt(0)
+}
 
-	return l.input[l.peek]
+func (l *Lexer) peekCharAt(offset int) byte {
+	return l.byteAt(l.peek + offset)
 }
 
 func isLetter(c byte) bool {
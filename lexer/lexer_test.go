@@ -1,8 +1,12 @@
 package lexer
 
 import (
+	"errors"
+	"io"
 	"monkey/token"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestNextToken(t *testing.T) {
@@ -33,90 +37,276 @@ func TestNextToken(t *testing.T) {
 `
 
 	expectedTokens := []token.Token{
-		{token.LET, "let"},
-		{token.ID, "five"},
-		{token.ASSIGN, "="},
-		{token.NUMBER, "5"},
-		{token.LET, "let"},
-		{token.ID, "ten"},
-		{token.ASSIGN, "="},
-		{token.NUMBER, "10"},
-		{token.LET, "let"},
-		{token.ID, "add"},
-		{token.ASSIGN, "="},
-		{token.FUNCTION, "fn"},
-		{token.LPAREN, "("},
-		{token.ID, "x"},
-		{token.COMMA, ","},
-		{token.ID, "y"},
-		{token.RPAREN, ")"},
-		{token.LBRACE, "{"},
-		{token.RETURN, "return"},
-		{token.ID, "x"},
-		{token.PLUS, "+"},
-		{token.ID, "y"},
-		{token.SEMICOLON, ";"},
-		{token.RBRACE, "}"},
-		{token.LET, "let"},
-		{token.ID, "result"},
-		{token.ASSIGN, "="},
-		{token.ID, "add"},
-		{token.LPAREN, "("},
-		{token.ID, "five"},
-		{token.COMMA, ","},
-		{token.ID, "ten"},
-		{token.RPAREN, ")"},
-		{token.SEMICOLON, ";"},
-		{token.BANG, "!"},
-		{token.MINUS, "-"},
-		{token.SLASH, "/"},
-		{token.ASTERISK, "*"},
-		{token.NUMBER, "5"},
-		{token.SEMICOLON, ";"},
-		{token.NUMBER, "5"},
-		{token.LESS, "<"},
-		{token.NUMBER, "10"},
-		{token.GREATER, ">"},
-		{token.NUMBER, "5"},
-		{token.SEMICOLON, ";"},
-		{token.IF, "if"},
-		{token.LPAREN, "("},
-		{token.NUMBER, "5"},
-		{token.LESS, "<"},
-		{token.NUMBER, "10"},
-		{token.RPAREN, ")"},
-		{token.LBRACE, "{"},
-		{token.RETURN, "return"},
-		{token.TRUE, "true"},
-		{token.SEMICOLON, ";"},
-		{token.RBRACE, "}"},
-		{token.ELSE, "else"},
-		{token.LBRACE, "{"},
-		{token.RETURN, "return"},
-		{token.FALSE, "false"},
-		{token.SEMICOLON, ";"},
-		{token.RBRACE, "}"},
-		{token.NUMBER, "10"},
-		{token.EQUAL, "=="},
-		{token.NUMBER, "10"},
-		{token.NUMBER, "10"},
-		{token.NOT_EQUAL, "!="},
-		{token.NUMBER, "9"},
-		{token.STRING, "foobar"},
-		{token.STRING, "foo bar"},
-		{token.LBRACKET, "["},
-		{token.NUMBER, "1"},
-		{token.COMMA, ","},
-		{token.NUMBER, "2"},
-		{token.RBRACKET, "]"},
-		{token.SEMICOLON, ";"},
-		{token.EOF, ""},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.ID, Literal: "five"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.NUMBER, Literal: "5"},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.ID, Literal: "ten"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.NUMBER, Literal: "10"},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.ID, Literal: "add"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.FUNCTION, Literal: "fn"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.ID, Literal: "x"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.ID, Literal: "y"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.RETURN, Literal: "return"},
+		{Type: token.ID, Literal: "x"},
+		{Type: token.PLUS, Literal: "+"},
+		{Type: token.ID, Literal: "y"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.ID, Literal: "result"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.ID, Literal: "add"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.ID, Literal: "five"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.ID, Literal: "ten"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.BANG, Literal: "!"},
+		{Type: token.MINUS, Literal: "-"},
+		{Type: token.SLASH, Literal: "/"},
+		{Type: token.ASTERISK, Literal: "*"},
+		{Type: token.NUMBER, Literal: "5"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.NUMBER, Literal: "5"},
+		{Type: token.LESS, Literal: "<"},
+		{Type: token.NUMBER, Literal: "10"},
+		{Type: token.GREATER, Literal: ">"},
+		{Type: token.NUMBER, Literal: "5"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IF, Literal: "if"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.NUMBER, Literal: "5"},
+		{Type: token.LESS, Literal: "<"},
+		{Type: token.NUMBER, Literal: "10"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.RETURN, Literal: "return"},
+		{Type: token.TRUE, Literal: "true"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.ELSE, Literal: "else"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.RETURN, Literal: "return"},
+		{Type: token.FALSE, Literal: "false"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.NUMBER, Literal: "10"},
+		{Type: token.EQUAL, Literal: "=="},
+		{Type: token.NUMBER, Literal: "10"},
+		{Type: token.NUMBER, Literal: "10"},
+		{Type: token.NOT_EQUAL, Literal: "!="},
+		{Type: token.NUMBER, Literal: "9"},
+		{Type: token.STRING, Literal: "foobar"},
+		{Type: token.STRING, Literal: "foo bar"},
+		{Type: token.LBRACKET, Literal: "["},
+		{Type: token.NUMBER, Literal: "1"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.NUMBER, Literal: "2"},
+		{Type: token.RBRACKET, Literal: "]"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.EOF, Literal: ""},
 	}
 
 	lexer := New(input)
 	assertTokens(t, expectedTokens, lexer)
 }
 
+func TestSliceTokens(t *testing.T) {
+	input := `a[1:-1]`
+
+	expectedTokens := []token.Token{
+		{Type: token.ID, Literal: "a"},
+		{Type: token.LBRACKET, Literal: "["},
+		{Type: token.NUMBER, Literal: "1"},
+		{Type: token.COLON, Literal: ":"},
+		{Type: token.MINUS, Literal: "-"},
+		{Type: token.NUMBER, Literal: "1"},
+		{Type: token.RBRACKET, Literal: "]"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lexer := New(input)
+	assertTokens(t, expectedTokens, lexer)
+}
+
+func TestEllipsisTokens(t *testing.T) {
+	input := `let [a, ...rest] = b; ..`
+
+	expectedTokens := []token.Token{
+		{Type: token.LET, Literal: "let"},
+		{Type: token.LBRACKET, Literal: "["},
+		{Type: token.ID, Literal: "a"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.ELLIPSIS, Literal: "..."},
+		{Type: token.ID, Literal: "rest"},
+		{Type: token.RBRACKET, Literal: "]"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.ID, Literal: "b"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.ILLEGAL, Literal: "."},
+		{Type: token.ILLEGAL, Literal: "."},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lexer := New(input)
+	assertTokens(t, expectedTokens, lexer)
+}
+
+func TestMatchTokens(t *testing.T) {
+	input := `match (x) { 1 | 2 => 3, _ => 4 }`
+
+	expectedTokens := []token.Token{
+		{Type: token.MATCH, Literal: "match"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.ID, Literal: "x"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.NUMBER, Literal: "1"},
+		{Type: token.PIPE, Literal: "|"},
+		{Type: token.NUMBER, Literal: "2"},
+		{Type: token.ARROW, Literal: "=>"},
+		{Type: token.NUMBER, Literal: "3"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.ID, Literal: "_"},
+		{Type: token.ARROW, Literal: "=>"},
+		{Type: token.NUMBER, Literal: "4"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lexer := New(input)
+	assertTokens(t, expectedTokens, lexer)
+}
+
+func TestUnterminatedString(t *testing.T) {
+	input := `let s = "foo
+bar`
+
+	expectedTokens := []token.Token{
+		{Type: token.LET, Literal: "let"},
+		{Type: token.ID, Literal: "s"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.ILLEGAL, Literal: "\"foo\nbar"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lexer := New(input)
+	assertTokens(t, expectedTokens, lexer)
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "let five = 5;\nlet s = \"a b\";\n  five == s"
+
+	expectedPositions := []struct {
+		literal  string
+		position token.Position
+	}{
+		{"let", token.Position{Offset: 0, Line: 1, Column: 1}},
+		{"five", token.Position{Offset: 4, Line: 1, Column: 5}},
+		{"=", token.Position{Offset: 9, Line: 1, Column: 10}},
+		{"5", token.Position{Offset: 11, Line: 1, Column: 12}},
+		{";", token.Position{Offset: 12, Line: 1, Column: 13}},
+		{"let", token.Position{Offset: 14, Line: 2, Column: 1}},
+		{"s", token.Position{Offset: 18, Line: 2, Column: 5}},
+		{"=", token.Position{Offset: 20, Line: 2, Column: 7}},
+		{"a b", token.Position{Offset: 22, Line: 2, Column: 9}},
+		{";", token.Position{Offset: 27, Line: 2, Column: 14}},
+		{"five", token.Position{Offset: 31, Line: 3, Column: 3}},
+		{"==", token.Position{Offset: 36, Line: 3, Column: 8}},
+		{"s", token.Position{Offset: 39, Line: 3, Column: 11}},
+		{"", token.Position{Offset: 40, Line: 3, Column: 12}},
+	}
+
+	lexer := New(input)
+	for i, expected := range expectedPositions {
+		actual := lexer.NextToken()
+		if actual.Literal != expected.literal || actual.Position != expected.position {
+			t.Fatalf("case[%d] failed, expected=%q at %+v, actual=%q at %+v", i, expected.literal, expected.position, actual.Literal, actual.Position)
+		}
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	long := strings.Repeat("x", 3*BUFFER_SIZE)
+	generated := strings.Repeat("let value_1 = [1, 22, 333][0:2];\n\t", BUFFER_SIZE/8)
+
+	tests := []struct {
+		name   string
+		input  string
+		reader func(io.Reader) io.Reader
+	}{
+		{"empty", "", nil},
+		{"program", "let s = \"a b\";\nmatch (s) { [x, ...r] | 1 => x != r, _ => !s }", nil},
+		{"one byte", "let five = 5;\nlet s = \"a b\";\n  five == s => ...", iotest.OneByteReader},
+		{"half", generated, iotest.HalfReader},
+		{"long string", "let s = \"" + long + "\"; let " + long + " = 1;", nil},
+		{"unterminated", "let s = \"" + long, iotest.DataErrReader},
+		{"whitespace", strings.Repeat(" \n", 4*BUFFER_SIZE) + "x", nil},
+	}
+
+	for _, tt := range tests {
+		var reader io.Reader = strings.NewReader(tt.input)
+		if tt.reader != nil {
+			reader = tt.reader(reader)
+		}
+
+		expected := New(tt.input)
+		actual := NewReader(reader)
+		for i := 0; ; i++ {
+			expectedToken := expected.NextToken()
+			actualToken := actual.NextToken()
+			if actualToken != expectedToken {
+				t.Fatalf("%s : token[%d] expected : %+v, but was actual : %+v", tt.name, i, expectedToken, actualToken)
+			}
+			if expectedToken.Type == token.EOF {
+				break
+			}
+		}
+
+		if actual.Err() != nil {
+			t.Errorf("%s : Err() expected : nil, but was actual : %s", tt.name, actual.Err())
+		}
+	}
+}
+
+func TestNewReaderBuffer(t *testing.T) {
+	lexer := NewReader(strings.NewReader(strings.Repeat("let x = 1;\n", 100*BUFFER_SIZE)))
+
+	for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+		if cap(lexer.input) > BUFFER_SIZE {
+			t.Fatalf("buffer at %s expected at most : %d, but was actual : %d", tok.Position, BUFFER_SIZE, cap(lexer.input))
+		}
+	}
+}
+
+func TestNewReaderError(t *testing.T) {
+	failure := errors.New("disk failure")
+	lexer := NewReader(io.MultiReader(strings.NewReader("let x = 1"), iotest.ErrReader(failure)))
+
+	expectedTokens := []token.Token{
+		{Type: token.LET, Literal: "let"},
+		{Type: token.ID, Literal: "x"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.NUMBER, Literal: "1"},
+		{Type: token.EOF, Literal: ""},
+	}
+	assertTokens(t, expectedTokens, lexer)
+
+	if lexer.Err() != failure {
+		t.Errorf("Err() expected : %s, but was actual : %v", failure, lexer.Err())
+	}
+}
+
 func assertTokens(t *testing.T, expectedTokens []token.Token, lexer *Lexer) {
 	for i, expected := range expectedTokens {
 		actualToken := lexer.NextToken()
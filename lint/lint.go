@@ -0,0 +1,100 @@
+package lint
+
+import (
+	"fmt"
+	"monkey/analysis"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/token"
+	"sort"
+)
+
+const (
+	ERROR   = "error"
+	WARNING = "warning"
+)
+
+type Diagnostic struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Offset   int    `json:"offset"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", d.File, d.Line, d.Column, d.Severity, d.Message, d.Rule)
+}
+
+type Rule struct {
+	Name        string
+	Description string
+	Severity    string
+	check       func(p *pass)
+}
+
+var rules = []Rule{
+	{"unused", "let bindings that are never referenced", WARNING, checkUnused},
+	{"shadow-builtin", "bindings that shadow a builtin function", WARNING, checkShadowedBuiltins},
+	{"unreachable", "statements after a return statement", WARNING, checkUnreachable},
+	{"arity", "calls with the wrong number of arguments to known functions", ERROR, checkArity},
+}
+
+func Rules() []Rule {
+	return append([]Rule{}, rules...)
+}
+
+type Config struct {
+	Disabled map[string]bool
+	Builtins evaluator.Builtins
+}
+
+func (c Config) Enabled(rule string) bool {
+	return !c.Disabled[rule]
+}
+
+type pass struct {
+	program     *ast.Program
+	analysis    *analysis.Result
+	builtins    evaluator.Builtins
+	rule        Rule
+	diagnostics []Diagnostic
+}
+
+func (p *pass) report(position token.Position, format string, a ...interface{}) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Line:     position.Line,
+		Column:   position.Column,
+		Offset:   position.Offset,
+		Rule:     p.rule.Name,
+		Severity: p.rule.Severity,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+func Lint(program *ast.Program, config Config) []Diagnostic {
+	p := &pass{
+		program:  program,
+		analysis: analysis.Analyze(program),
+		builtins: evaluator.NewBuiltins(),
+	}
+
+	for name, builtin := range config.Builtins {
+		p.builtins[name] = builtin
+	}
+
+	for _, rule := range rules {
+		if config.Enabled(rule.Name) {
+			p.rule = rule
+			rule.check(p)
+		}
+	}
+
+	sort.SliceStable(p.diagnostics, func(i, j int) bool {
+		return p.diagnostics[i].Offset < p.diagnostics[j].Offset
+	})
+
+	return p.diagnostics
+}
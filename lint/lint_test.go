@@ -0,0 +1,117 @@
+package lint
+
+import (
+	"fmt"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; x", nil},
+		{"let x = 1;", []string{"1:5 unused : x is declared but never used"}},
+		{"let _x = 1; let [a, _] = [1, 2]; a", nil},
+		{"const c = 1; let f = fn() { c }; f()", nil},
+		{"let f = fn(n) { if (n > 0) { f(n - 1) } }; f(3)", nil},
+		{"let len = fn(x) { x }; len(1)", []string{"1:5 shadow-builtin : let len shadows the builtin function len"}},
+		{"let f = fn(map) { map }; f(1)", []string{"1:12 shadow-builtin : parameter map shadows the builtin function map"}},
+		{"let f = fn() { return 1; 2 }; f()", []string{"1:26 unreachable : unreachable code after return"}},
+		{"return 1; let x = 2; x", []string{"1:11 unreachable : unreachable code after return"}},
+		{"let f = fn() { if (true) { return 1; } 2 }; f()", nil},
+		{"let f = fn(a, b) { a + b }; f(1)", []string{"1:29 arity : wrong number of arguments to f. got=1, want=2"}},
+		{"fn(a) { a }(1, 2)", []string{"1:1 arity : wrong number of arguments to function literal. got=2, want=1"}},
+		{"len(1, 2); push([])", []string{
+			"1:1 arity : wrong number of arguments to len. got=2, want=1",
+			"1:12 arity : wrong number of arguments to push. got=1, want=2",
+		}},
+		{"let g = fn(f) { f(1, 2) }; g(fn(x) { x })", nil},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("parser errors for %q : %v", tt.input, p.Errors())
+		}
+
+		var actual []string
+		for _, d := range Lint(program, Config{}) {
+			actual = append(actual, formatDiagnostic(d))
+		}
+
+		if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("lint of %q expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestLintBuiltinArity(t *testing.T) {
+	builtins := evaluator.Builtins{}
+	builtins.RegisterArity("twice", 1, nil)
+	builtins.Register("log", nil)
+	builtins["host"] = &object.Builtin{}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"twice(1); log(); log(1, 2, 3); host(1, 2)", nil},
+		{"twice(1, 2)", []string{"1:1 arity : wrong number of arguments to twice. got=2, want=1"}},
+		{"let twice = fn(a, b) { a + b }; twice(1, 2)", []string{"1:5 shadow-builtin : let twice shadows the builtin function twice"}},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+
+		var actual []string
+		for _, d := range Lint(program, Config{Builtins: builtins}) {
+			actual = append(actual, formatDiagnostic(d))
+		}
+
+		if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("lint of %q expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestLintConfig(t *testing.T) {
+	input := "let len = 1; let f = fn(x) { return x; x }; f(1, 2);"
+
+	tests := []struct {
+		config   Config
+		expected []string
+	}{
+		{Config{}, []string{"unused", "len", "unreachable", "arity"}},
+		{Config{Disabled: map[string]bool{"unused": true, "arity": true}}, []string{"len", "unreachable"}},
+		{Config{Builtins: evaluator.Builtins{"f": {Arity: 1, HasArity: true}}}, []string{"unused", "len", "f", "unreachable", "arity"}},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(input)).ParseProgram()
+
+		var actual []string
+		for _, d := range Lint(program, tt.config) {
+			switch d.Rule {
+			case "shadow-builtin":
+				actual = append(actual, strings.Fields(d.Message)[1])
+			default:
+				actual = append(actual, d.Rule)
+			}
+		}
+
+		if strings.Join(actual, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("lint with %+v expected : %q, but was actual : %q", tt.config, tt.expected, actual)
+		}
+	}
+}
+
+func formatDiagnostic(d Diagnostic) string {
+	return fmt.Sprintf("%d:%d %s : %s", d.Line, d.Column, d.Rule, d.Message)
+}
@@ -0,0 +1,93 @@
+package lint
+
+import (
+	"monkey/analysis"
+	"monkey/ast"
+	"monkey/token"
+	"strings"
+)
+
+func checkUnused(p *pass) {
+	for _, symbol := range p.analysis.Symbols {
+		if symbol.Kind != analysis.LET && symbol.Kind != analysis.CONST {
+			continue
+		}
+		if len(symbol.References) > 0 || strings.HasPrefix(symbol.Name, "_") {
+			continue
+		}
+
+		p.report(symbol.Identifier.Token.Position, "%s is declared but never used", symbol.Name)
+	}
+}
+
+func checkShadowedBuiltins(p *pass) {
+	for _, symbol := range p.analysis.Symbols {
+		if p.builtins[symbol.Name] != nil {
+			p.report(symbol.Identifier.Token.Position, "%s %s shadows the builtin function %s", symbol.Kind, symbol.Name, symbol.Name)
+		}
+	}
+}
+
+func checkUnreachable(p *pass) {
+	check := func(statements []ast.Statement) {
+		for i := 0; i+1 < len(statements); i++ {
+			if _, ok := statements[i].(*ast.ReturnStatement); ok {
+				p.report(statementPosition(statements[i+1]), "unreachable code after return")
+				return
+			}
+		}
+	}
+
+	ast.Inspect(p.program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.Program:
+			check(node.Statements)
+		case *ast.BlockStatement:
+			check(node.Statements)
+		}
+		return true
+	})
+}
+
+func checkArity(p *pass) {
+	ast.Inspect(p.program, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpression)
+		if !ok {
+			return true
+		}
+
+		switch function := call.Function.(type) {
+		case *ast.FunctionLiteral:
+			checkCall(p, call, function.Token, "function literal", len(function.Parameters))
+		case *ast.Identifier:
+			if symbol := p.analysis.Lookup(function); symbol != nil {
+				if literal := symbol.Function(); literal != nil {
+					checkCall(p, call, function.Token, function.Value, len(literal.Parameters))
+				}
+			} else if builtin := p.builtins[function.Value]; builtin != nil && builtin.HasArity {
+				checkCall(p, call, function.Token, function.Value, builtin.Arity)
+			}
+		}
+		return true
+	})
+}
+
+func checkCall(p *pass, call *ast.CallExpression, function token.Token, name string, want int) {
+	if got := len(call.Arguments); got != want {
+		p.report(function.Position, "wrong number of arguments to %s. got=%d, want=%d", name, got, want)
+	}
+}
+
+func statementPosition(statement ast.Statement) token.Position {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		return statement.Token.Position
+	case *ast.ReturnStatement:
+		return statement.Token.Position
+	case *ast.ExpressionStatement:
+		return statement.Token.Position
+	case *ast.BlockStatement:
+		return statement.Token.Position
+	}
+	return token.Position{}
+}
@@ -0,0 +1,98 @@
+package lsp
+
+import (
+	"monkey/analysis"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"sort"
+	"unicode/utf8"
+)
+
+type document struct {
+	uri        string
+	text       string
+	lineStarts []int
+	program    *ast.Program
+	errors     []parser.Error
+	analysis   *analysis.Result
+}
+
+func newDocument(uri string, text string) *document {
+	d := &document{uri: uri, text: text, lineStarts: []int{0}}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+
+	p := parser.New(lexer.New(text))
+	d.program = p.ParseProgram()
+	d.errors = p.Diagnostics()
+	d.analysis = analysis.Analyze(d.program)
+
+	return d
+}
+
+func (d *document) position(offset int) Position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+
+	line := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset }) - 1
+	character := 0
+	for _, r := range d.text[d.lineStarts[line]:offset] {
+		character += utf16Length(r)
+	}
+
+	return Position{Line: line, Character: character}
+}
+
+func (d *document) offset(position Position) int {
+	if position.Line < 0 {
+		return 0
+	}
+	if position.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+
+	offset := d.lineStarts[position.Line]
+	for character := 0; character < position.Character && offset < len(d.text); {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		if r == '\n' {
+			break
+		}
+		character += utf16Length(r)
+		offset += size
+	}
+
+	return offset
+}
+
+func (d *document) span(start int, length int) Range {
+	return Range{Start: d.position(start), End: d.position(start + length)}
+}
+
+func (d *document) identifierRange(identifier *ast.Identifier) Range {
+	return d.span(identifier.Token.Position.Offset, len(identifier.Value))
+}
+
+func (d *document) location(identifier *ast.Identifier) Location {
+	return Location{URI: d.uri, Range: d.identifierRange(identifier)}
+}
+
+func (d *document) symbolAt(position Position) (*ast.Identifier, *analysis.Symbol) {
+	identifier := d.analysis.IdentifierAt(d.offset(position))
+	if identifier == nil {
+		return nil, nil
+	}
+	return identifier, d.analysis.Lookup(identifier)
+}
+
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
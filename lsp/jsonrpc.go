@@ -0,0 +1,90 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type connection struct {
+	in  *bufio.Reader
+	out io.Writer
+	mu  sync.Mutex
+}
+
+func newConnection(in io.Reader, out io.Writer) *connection {
+	return &connection{in: bufio.NewReader(in), out: out}
+}
+
+func (c *connection) read() ([]byte, error) {
+	length := -1
+
+	for {
+		line, err := c.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header : %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid content length : %q", value)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("missing content length")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.in, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (c *connection) write(message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.out.Write(body)
+	return err
+}
+
+func (c *connection) reply(id *json.RawMessage, result interface{}) error {
+	return c.write(map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+func (c *connection) replyError(id *json.RawMessage, code int, message string) error {
+	return c.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   responseError{Code: code, Message: message},
+	})
+}
+
+func (c *connection) notify(method string, params interface{}) error {
+	return c.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
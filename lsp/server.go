@@ -0,0 +1,375 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"monkey/analysis"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/token"
+	"strings"
+)
+
+type Server struct {
+	conn      *connection
+	documents map[string]*document
+	builtins  map[string]bool
+}
+
+type handler func(s *Server, params json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"initialize":                  (*Server).initialize,
+	"shutdown":                    (*Server).shutdown,
+	"textDocument/didOpen":        (*Server).didOpen,
+	"textDocument/didChange":      (*Server).didChange,
+	"textDocument/didClose":       (*Server).didClose,
+	"textDocument/definition":     (*Server).definition,
+	"textDocument/references":     (*Server).references,
+	"textDocument/hover":          (*Server).hover,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+	"textDocument/completion":     (*Server).completion,
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	s := &Server{
+		conn:      newConnection(in, out),
+		documents: make(map[string]*document),
+		builtins:  make(map[string]bool),
+	}
+
+	for _, name := range evaluator.NewBuiltins().Names() {
+		s.builtins[name] = true
+	}
+
+	return s
+}
+
+func (s *Server) Serve() error {
+	for {
+		body, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var r request
+		if err := json.Unmarshal(body, &r); err != nil {
+			if err := s.conn.replyError(nil, parseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if r.Method == "exit" {
+			return nil
+		}
+
+		if err := s.dispatch(r); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) dispatch(r request) error {
+	handle, ok := handlers[r.Method]
+	if !ok {
+		if r.ID == nil {
+			return nil
+		}
+		return s.conn.replyError(r.ID, methodNotFound, "method not found : "+r.Method)
+	}
+
+	result, code, err := s.call(handle, r.Params)
+	if r.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return s.conn.replyError(r.ID, code, err.Error())
+	}
+	return s.conn.reply(r.ID, result)
+}
+
+func (s *Server) call(handle handler, params json.RawMessage) (result interface{}, code int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, code, err = nil, internalError, fmt.Errorf("internal error : %v", r)
+		}
+	}()
+
+	result, err = handle(s, params)
+	return result, invalidParams, err
+}
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync:       1,
+			DefinitionProvider:     true,
+			ReferencesProvider:     true,
+			HoverProvider:          true,
+			DocumentSymbolProvider: true,
+			CompletionProvider:     completionOptions{},
+		},
+		ServerInfo: serverInfo{Name: "monkey"},
+	}, nil
+}
+
+func (s *Server) shutdown(params json.RawMessage) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *Server) didOpen(params json.RawMessage) (interface{}, error) {
+	var p didOpenParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	return nil, s.update(p.TextDocument.URI, p.TextDocument.Text)
+}
+
+func (s *Server) didChange(params json.RawMessage) (interface{}, error) {
+	var p didChangeParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+
+	return nil, s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+}
+
+func (s *Server) didClose(params json.RawMessage) (interface{}, error) {
+	var p didCloseParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	delete(s.documents, p.TextDocument.URI)
+	return nil, s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         p.TextDocument.URI,
+		Diagnostics: []Diagnostic{},
+	})
+}
+
+func (s *Server) update(uri string, text string) error {
+	d := newDocument(uri, text)
+	s.documents[uri] = d
+
+	return s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: s.diagnostics(d),
+	})
+}
+
+func (s *Server) diagnostics(d *document) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	for _, err := range d.errors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.span(err.Position.Offset, 1),
+			Severity: severityError,
+			Source:   "monkey",
+			Message:  err.Message,
+		})
+	}
+	if len(d.errors) > 0 {
+		return diagnostics
+	}
+
+	for _, identifier := range d.analysis.Unresolved {
+		if s.builtins[identifier.Value] {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.identifierRange(identifier),
+			Severity: severityWarning,
+			Source:   "monkey",
+			Message:  "identifier not found : " + identifier.Value,
+		})
+	}
+
+	return diagnostics
+}
+
+func (s *Server) documentAt(params json.RawMessage) (*document, textDocumentPositionParams, error) {
+	var p textDocumentPositionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, p, err
+	}
+	return s.documents[p.TextDocument.URI], p, nil
+}
+
+func (s *Server) definition(params json.RawMessage) (interface{}, error) {
+	d, p, err := s.documentAt(params)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	_, symbol := d.symbolAt(p.Position)
+	if symbol == nil {
+		return nil, nil
+	}
+
+	return d.location(symbol.Identifier), nil
+}
+
+func (s *Server) references(params json.RawMessage) (interface{}, error) {
+	var p referenceParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	locations := []Location{}
+
+	d := s.documents[p.TextDocument.URI]
+	if d == nil {
+		return locations, nil
+	}
+
+	_, symbol := d.symbolAt(p.Position)
+	if symbol == nil {
+		return locations, nil
+	}
+
+	if p.Context.IncludeDeclaration {
+		locations = append(locations, d.location(symbol.Identifier))
+	}
+	for _, reference := range symbol.References {
+		locations = append(locations, d.location(reference))
+	}
+
+	return locations, nil
+}
+
+func (s *Server) hover(params json.RawMessage) (interface{}, error) {
+	d, p, err := s.documentAt(params)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	identifier, symbol := d.symbolAt(p.Position)
+	if identifier == nil {
+		return nil, nil
+	}
+
+	var description string
+	switch {
+	case symbol != nil:
+		description = describe(symbol, len(d.errors) == 0)
+	case s.builtins[identifier.Value]:
+		description = "builtin " + identifier.Value
+	default:
+		return nil, nil
+	}
+
+	return Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```monkey\n" + description + "\n```"},
+		Range:    d.identifierRange(identifier),
+	}, nil
+}
+
+func describe(symbol *analysis.Symbol, complete bool) string {
+	if function := symbol.Function(); function != nil {
+		return string(symbol.Kind) + " " + symbol.Name + " = " + signature(function)
+	}
+
+	switch symbol.Kind {
+	case analysis.LET, analysis.CONST:
+		if symbol.Statement.Pattern != nil || symbol.Statement.Value == nil || !complete {
+			return string(symbol.Kind) + " " + symbol.Name
+		}
+		return string(symbol.Kind) + " " + symbol.Name + " = " + symbol.Statement.Value.String()
+	case analysis.BINDING:
+		return "match binding " + symbol.Name
+	default:
+		return string(symbol.Kind) + " " + symbol.Name
+	}
+}
+
+func signature(function *ast.FunctionLiteral) string {
+	parameters := make([]string, len(function.Parameters))
+	for i, parameter := range function.Parameters {
+		parameters[i] = parameter.Value
+	}
+	return "fn(" + strings.Join(parameters, ", ") + ")"
+}
+
+func (s *Server) documentSymbol(params json.RawMessage) (interface{}, error) {
+	var p documentSymbolParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	symbols := []DocumentSymbol{}
+
+	d := s.documents[p.TextDocument.URI]
+	if d == nil {
+		return symbols, nil
+	}
+
+	for _, symbol := range d.analysis.Symbols {
+		if symbol.Kind != analysis.LET && symbol.Kind != analysis.CONST {
+			continue
+		}
+
+		start := symbol.Statement.Token.Position.Offset
+		end := symbol.Identifier.Token.Position.Offset + len(symbol.Name)
+
+		documentSymbol := DocumentSymbol{
+			Name:           symbol.Name,
+			Kind:           symbolKindVariable,
+			Range:          d.span(start, end-start),
+			SelectionRange: d.identifierRange(symbol.Identifier),
+		}
+		if symbol.Kind == analysis.CONST {
+			documentSymbol.Kind = symbolKindConstant
+		}
+		if function := symbol.Function(); function != nil {
+			documentSymbol.Kind = symbolKindFunction
+			documentSymbol.Detail = signature(function)
+		}
+
+		symbols = append(symbols, documentSymbol)
+	}
+
+	return symbols, nil
+}
+
+func (s *Server) completion(params json.RawMessage) (interface{}, error) {
+	d, _, err := s.documentAt(params)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []CompletionItem{}
+	for _, name := range evaluator.NewBuiltins().Names() {
+		items = append(items, CompletionItem{Label: name, Kind: completionKindFunction, Detail: "builtin"})
+	}
+	for _, keyword := range token.Keywords() {
+		items = append(items, CompletionItem{Label: keyword, Kind: completionKindKeyword})
+	}
+
+	if d != nil {
+		seen := make(map[string]bool)
+		for _, symbol := range d.analysis.Symbols {
+			if seen[symbol.Name] || symbol.Kind == analysis.BINDING || symbol.Kind == analysis.PARAMETER {
+				continue
+			}
+			seen[symbol.Name] = true
+
+			item := CompletionItem{Label: symbol.Name, Kind: completionKindVariable, Detail: string(symbol.Kind)}
+			if function := symbol.Function(); function != nil {
+				item.Kind = completionKindFunction
+				item.Detail = signature(function)
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,402 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+const uri = "file:///test.mk"
+
+const source = `let add = fn(x, y) {
+  x + y
+};
+const limit = 10;
+let total = add(1, limit);
+add(total, missing);
+len("é" + "abc");`
+
+type client struct {
+	t       *testing.T
+	conn    *connection
+	id      int
+	pending []map[string]json.RawMessage
+	done    chan error
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{t: t, conn: newConnection(clientIn, clientOut), done: make(chan error, 1)}
+
+	go func() {
+		c.done <- NewServer(serverIn, serverOut).Serve()
+		serverOut.Close()
+	}()
+
+	return c
+}
+
+func (c *client) send(message map[string]interface{}) {
+	message["jsonrpc"] = "2.0"
+	if err := c.conn.write(message); err != nil {
+		c.t.Fatalf("could not write message : %s", err)
+	}
+}
+
+func (c *client) receive() map[string]json.RawMessage {
+	body, err := c.conn.read()
+	if err != nil {
+		c.t.Fatalf("could not read message : %s", err)
+	}
+
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		c.t.Fatalf("invalid message %s : %s", body, err)
+	}
+	return message
+}
+
+func (c *client) call(method string, params interface{}, result interface{}) {
+	c.id++
+	c.send(map[string]interface{}{"id": c.id, "method": method, "params": params})
+
+	for {
+		message := c.receive()
+		if _, ok := message["method"]; ok {
+			c.pending = append(c.pending, message)
+			continue
+		}
+
+		if e, ok := message["error"]; ok {
+			c.t.Fatalf("%s returned error : %s", method, e)
+		}
+		if err := json.Unmarshal(message["result"], result); err != nil {
+			c.t.Fatalf("invalid %s result %s : %s", method, message["result"], err)
+		}
+		return
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.send(map[string]interface{}{"method": method, "params": params})
+}
+
+func (c *client) diagnostics() publishDiagnosticsParams {
+	var message map[string]json.RawMessage
+	if len(c.pending) > 0 {
+		message, c.pending = c.pending[0], c.pending[1:]
+	} else {
+		message = c.receive()
+	}
+
+	var method string
+	json.Unmarshal(message["method"], &method)
+	if method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("notification expected : textDocument/publishDiagnostics, but was actual : %s", method)
+	}
+
+	var params publishDiagnosticsParams
+	json.Unmarshal(message["params"], &params)
+	return params
+}
+
+func position(line int, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     Position{Line: line, Character: character},
+	}
+}
+
+func open(t *testing.T, text string) *client {
+	c := newClient(t)
+
+	var initialized initializeResult
+	c.call("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}, &initialized)
+	if !initialized.Capabilities.DefinitionProvider || initialized.Capabilities.TextDocumentSync != 1 {
+		t.Fatalf("unexpected capabilities : %+v", initialized.Capabilities)
+	}
+	c.notify("initialized", map[string]interface{}{})
+
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "monkey", "version": 1, "text": text},
+	})
+
+	return c
+}
+
+func (c *client) close() {
+	var result interface{}
+	c.call("shutdown", nil, &result)
+	c.notify("exit", nil)
+
+	if err := <-c.done; err != nil {
+		c.t.Errorf("serve returned error : %s", err)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := open(t, source)
+	defer c.close()
+
+	diagnostics := c.diagnostics()
+	if diagnostics.URI != uri || len(diagnostics.Diagnostics) != 1 {
+		t.Fatalf("diagnostics expected : 1, but was actual : %+v", diagnostics)
+	}
+
+	expected := Diagnostic{
+		Range:    Range{Start: Position{Line: 5, Character: 11}, End: Position{Line: 5, Character: 18}},
+		Severity: severityWarning,
+		Source:   "monkey",
+		Message:  "identifier not found : missing",
+	}
+	if diagnostics.Diagnostics[0] != expected {
+		t.Errorf("diagnostic expected : %+v, but was actual : %+v", expected, diagnostics.Diagnostics[0])
+	}
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]interface{}{{"text": "let x = ;\nlet y 1;"}},
+	})
+
+	diagnostics = c.diagnostics()
+	var messages []string
+	for _, diagnostic := range diagnostics.Diagnostics {
+		if diagnostic.Severity != severityError {
+			t.Errorf("severity expected : %d, but was actual : %d", severityError, diagnostic.Severity)
+		}
+		messages = append(messages, diagnostic.Message)
+	}
+
+	expectedMessages := []string{
+		"no prefix parse function for ;",
+		"next token expected : =, but was actual : NUMBER",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expectedMessages, "\n") {
+		t.Errorf("messages expected : %q, but was actual : %q", expectedMessages, messages)
+	}
+	if start := diagnostics.Diagnostics[1].Range.Start; start != (Position{Line: 1, Character: 6}) {
+		t.Errorf("position expected : 1:6, but was actual : %+v", start)
+	}
+
+	c.notify("textDocument/didClose", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri}})
+	if diagnostics := c.diagnostics(); len(diagnostics.Diagnostics) != 0 {
+		t.Errorf("diagnostics after close expected : 0, but was actual : %d", len(diagnostics.Diagnostics))
+	}
+}
+
+func TestDefinitionAndReferences(t *testing.T) {
+	c := open(t, source)
+	defer c.close()
+	c.diagnostics()
+
+	var definition Location
+	c.call("textDocument/definition", position(4, 13), &definition)
+
+	expected := Range{Start: Position{Line: 0, Character: 4}, End: Position{Line: 0, Character: 7}}
+	if definition.URI != uri || definition.Range != expected {
+		t.Errorf("definition expected : %+v, but was actual : %+v", expected, definition)
+	}
+
+	var parameter Location
+	c.call("textDocument/definition", position(1, 6), &parameter)
+	if parameter.Range.Start != (Position{Line: 0, Character: 16}) {
+		t.Errorf("parameter definition expected : 0:16, but was actual : %+v", parameter.Range.Start)
+	}
+
+	var missing *Location
+	c.call("textDocument/definition", position(5, 12), &missing)
+	if missing != nil {
+		t.Errorf("definition of missing expected : null, but was actual : %+v", missing)
+	}
+
+	params := position(0, 5)
+	params["context"] = map[string]interface{}{"includeDeclaration": true}
+
+	var references []Location
+	c.call("textDocument/references", params, &references)
+
+	var lines []int
+	for _, reference := range references {
+		lines = append(lines, reference.Range.Start.Line)
+	}
+	if len(lines) != 3 || lines[0] != 0 || lines[1] != 4 || lines[2] != 5 {
+		t.Errorf("reference lines expected : [0 4 5], but was actual : %v", lines)
+	}
+}
+
+func TestHover(t *testing.T) {
+	c := open(t, source)
+	defer c.close()
+	c.diagnostics()
+
+	tests := []struct {
+		line      int
+		character int
+		expected  string
+	}{
+		{4, 12, "let add = fn(x, y)"},
+		{4, 20, "const limit = 10"},
+		{1, 2, "parameter x"},
+		{6, 1, "builtin len"},
+		{6, 12, ""},
+	}
+
+	for _, tt := range tests {
+		var hover *Hover
+		c.call("textDocument/hover", position(tt.line, tt.character), &hover)
+
+		if tt.expected == "" {
+			if hover != nil {
+				t.Errorf("hover at %d:%d expected : null, but was actual : %+v", tt.line, tt.character, hover)
+			}
+			continue
+		}
+
+		expected := "```monkey\n" + tt.expected + "\n```"
+		if hover == nil || hover.Contents.Value != expected {
+			t.Errorf("hover at %d:%d expected : %q, but was actual : %+v", tt.line, tt.character, expected, hover)
+		}
+	}
+}
+
+func TestHoverSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"let x = 1 + ;", "let x"},
+		{"let f = fn(a, { a }; f(", "let f"},
+	}
+
+	for _, tt := range tests {
+		c := open(t, tt.text)
+		c.diagnostics()
+
+		var hover *Hover
+		c.call("textDocument/hover", position(0, 4), &hover)
+
+		expected := "```monkey\n" + tt.expected + "\n```"
+		if hover == nil || hover.Contents.Value != expected {
+			t.Errorf("hover of %q expected : %q, but was actual : %+v", tt.text, expected, hover)
+		}
+
+		c.close()
+	}
+}
+
+func TestHandlerPanic(t *testing.T) {
+	handlers["test/panic"] = func(s *Server, params json.RawMessage) (interface{}, error) {
+		panic("broken handler")
+	}
+	defer delete(handlers, "test/panic")
+
+	c := open(t, source)
+	defer c.close()
+	c.diagnostics()
+
+	c.id++
+	c.send(map[string]interface{}{"id": c.id, "method": "test/panic"})
+
+	message := c.receive()
+	var e responseError
+	json.Unmarshal(message["error"], &e)
+	if e.Code != internalError || e.Message != "internal error : broken handler" {
+		t.Errorf("error expected : %d internal error : broken handler, but was actual : %d %s", internalError, e.Code, e.Message)
+	}
+
+	var hover *Hover
+	c.call("textDocument/hover", position(4, 20), &hover)
+	if hover == nil {
+		t.Errorf("hover after panic expected : const limit = 10, but was actual : null")
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	c := open(t, source)
+	defer c.close()
+	c.diagnostics()
+
+	var symbols []DocumentSymbol
+	c.call("textDocument/documentSymbol", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	}, &symbols)
+
+	expected := []DocumentSymbol{
+		{
+			Name:           "add",
+			Detail:         "fn(x, y)",
+			Kind:           symbolKindFunction,
+			Range:          Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: 0, Character: 7}},
+			SelectionRange: Range{Start: Position{Line: 0, Character: 4}, End: Position{Line: 0, Character: 7}},
+		},
+		{
+			Name:           "limit",
+			Kind:           symbolKindConstant,
+			Range:          Range{Start: Position{Line: 3, Character: 0}, End: Position{Line: 3, Character: 11}},
+			SelectionRange: Range{Start: Position{Line: 3, Character: 6}, End: Position{Line: 3, Character: 11}},
+		},
+		{
+			Name:           "total",
+			Kind:           symbolKindVariable,
+			Range:          Range{Start: Position{Line: 4, Character: 0}, End: Position{Line: 4, Character: 9}},
+			SelectionRange: Range{Start: Position{Line: 4, Character: 4}, End: Position{Line: 4, Character: 9}},
+		},
+	}
+
+	if len(symbols) != len(expected) {
+		t.Fatalf("symbols expected : %d, but was actual : %+v", len(expected), symbols)
+	}
+	for i := range expected {
+		if symbols[i] != expected[i] {
+			t.Errorf("symbol[%d] expected : %+v, but was actual : %+v", i, expected[i], symbols[i])
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	c := open(t, source)
+	defer c.close()
+	c.diagnostics()
+
+	var items []CompletionItem
+	c.call("textDocument/completion", position(6, 0), &items)
+
+	labels := make(map[string]CompletionItem)
+	for _, item := range items {
+		labels[item.Label] = item
+	}
+
+	for _, name := range []string{"len", "push", "map", "reduce"} {
+		if item, ok := labels[name]; !ok || item.Kind != completionKindFunction {
+			t.Errorf("builtin %s expected in completion, but was actual : %+v", name, item)
+		}
+	}
+	if item := labels["add"]; item.Detail != "fn(x, y)" {
+		t.Errorf("add completion expected : fn(x, y), but was actual : %+v", item)
+	}
+	if _, ok := labels["let"]; !ok {
+		t.Errorf("keyword let expected in completion")
+	}
+	if _, ok := labels["x"]; ok {
+		t.Errorf("parameter x not expected in completion")
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	c := open(t, source)
+	defer c.close()
+	c.diagnostics()
+
+	c.id++
+	c.send(map[string]interface{}{"id": c.id, "method": "workspace/unknown"})
+
+	message := c.receive()
+	var e responseError
+	json.Unmarshal(message["error"], &e)
+	if e.Code != methodNotFound {
+		t.Errorf("error code expected : %d, but was actual : %d", methodNotFound, e.Code)
+	}
+}
@@ -1,5 +1,7 @@
 package object
 
+import "sort"
+
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	environment := NewEnvironment()
 	environment.outer = outer
@@ -8,12 +10,20 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s}
+	c := make(map[string]bool)
+	return &Environment{store: s, constants: c}
+}
+
+func NewFrame(outer *Environment, size int) *Environment {
+	return &Environment{slots: make([]Object, size), outer: outer}
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store     map[string]Object
+	constants map[string]bool
+	slots     []Object
+	constant  []bool
+	outer     *Environment
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -25,6 +35,57 @@ func (e *Environment) Get(name string) (Object, bool) {
 }
 
 func (e *Environment) Set(name string, value Object) Object {
+	if e.store == nil {
+		e.store = make(map[string]Object)
+	}
 	e.store[name] = value
 	return value
 }
+
+func (e *Environment) SetConstant(name string, value Object) Object {
+	if e.constants == nil {
+		e.constants = make(map[string]bool)
+	}
+	e.constants[name] = true
+	return e.Set(name, value)
+}
+
+func (e *Environment) IsConstant(name string) bool {
+	return e.constants[name]
+}
+
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *Environment) GetAt(depth int, slot int) (Object, bool) {
+	environment := e
+	for i := 0; i < depth; i++ {
+		environment = environment.outer
+	}
+
+	value := environment.slots[slot]
+	return value, value != nil
+}
+
+func (e *Environment) SetAt(slot int, value Object) Object {
+	e.slots[slot] = value
+	return value
+}
+
+func (e *Environment) SetConstantAt(slot int, value Object) Object {
+	if e.constant == nil {
+		e.constant = make([]bool, len(e.slots))
+	}
+	e.constant[slot] = true
+	return e.SetAt(slot, value)
+}
+
+func (e *Environment) IsConstantAt(slot int) bool {
+	return e.constant != nil && e.constant[slot]
+}
@@ -0,0 +1,78 @@
+package object
+
+type Comparable interface {
+	Equals(other Object) bool
+}
+
+func Equal(left Object, right Object) bool {
+	if comparable, ok := left.(Comparable); ok {
+		return comparable.Equals(right)
+	}
+	return left == right
+}
+
+func (i *Integer) Equals(other Object) bool {
+	o, ok := other.(*Integer)
+	return ok && i.Value == o.Value
+}
+
+func (b *Boolean) Equals(other Object) bool {
+	o, ok := other.(*Boolean)
+	return ok && b.Value == o.Value
+}
+
+func (n *Null) Equals(other Object) bool {
+	_, ok := other.(*Null)
+	return ok
+}
+
+func (s *String) Equals(other Object) bool {
+	o, ok := other.(*String)
+	return ok && (s == o || s.Value == o.Value)
+}
+
+func (f *Function) Equals(other Object) bool {
+	o, ok := other.(*Function)
+	return ok && f == o
+}
+
+func (b *Builtin) Equals(other Object) bool {
+	o, ok := other.(*Builtin)
+	return ok && b == o
+}
+
+func (a *Array) Equals(other Object) bool {
+	o, ok := other.(*Array)
+	if !ok || a.Len() != o.Len() {
+		return false
+	}
+	if a == o {
+		return true
+	}
+
+	elements := o.Elements()
+	for i, element := range a.Elements() {
+		if !Equal(element, elements[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Hash) Equals(other Object) bool {
+	o, ok := other.(*Hash)
+	if !ok || len(h.Pairs) != len(o.Pairs) {
+		return false
+	}
+	if h == o {
+		return true
+	}
+
+	for key, pair := range h.Pairs {
+		otherPair, ok := o.Pairs[key]
+		if !ok || !Equal(pair.Key, otherPair.Key) || !Equal(pair.Value, otherPair.Value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package object
+
+import "sync"
+
+const (
+	SMALL_INTEGER_MIN    = -128
+	SMALL_INTEGER_MAX    = 1024
+	MAX_INTERNED_STRINGS = 4096
+)
+
+var smallIntegers = func() []*Integer {
+	integers := make([]*Integer, SMALL_INTEGER_MAX-SMALL_INTEGER_MIN+1)
+	for i := range integers {
+		integers[i] = &Integer{Value: int64(i + SMALL_INTEGER_MIN)}
+	}
+	return integers
+}()
+
+func NewInteger(value int64) *Integer {
+	if value >= SMALL_INTEGER_MIN && value <= SMALL_INTEGER_MAX {
+		return smallIntegers[value-SMALL_INTEGER_MIN]
+	}
+	return &Integer{Value: value}
+}
+
+var interned = struct {
+	sync.Mutex
+	strings map[string]*String
+}{strings: make(map[string]*String)}
+
+func InternString(value string) *String {
+	s, _ := NewInternedString(value)
+	return s
+}
+
+func NewInternedString(value string) (*String, bool) {
+	interned.Lock()
+	defer interned.Unlock()
+
+	if s, ok := interned.strings[value]; ok {
+		return s, false
+	}
+
+	s := &String{Value: value}
+	if len(interned.strings) < MAX_INTERNED_STRINGS {
+		interned.strings[value] = s
+	}
+	return s, true
+}
@@ -3,6 +3,7 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"hash/fnv"
 	"monkey/ast"
 	"strings"
 )
@@ -15,10 +16,12 @@ const (
 	NULL_OBJECT         = "NULL"
 	RETURN_VALUE_OBJECT = "RETURN_VALUE"
 	ERROR_OBJECT        = "ERROR"
+	LIMIT_ERROR_OBJECT  = "LIMIT_ERROR"
 	FUNCTION_OBJECT     = "FUNCTION"
 	STRING_OBJECT       = "STRING"
 	BUILTIN_OBJECT      = "BUILTIN"
 	ARRAY_OBJECT        = "ARRAY"
+	HASH_OBJECT         = "HASH"
 )
 
 type Object interface {
@@ -84,10 +87,24 @@ func (e *Error) Inspect() string {
 	return "ERROR :" + e.Message
 }
 
+type LimitError struct {
+	Limit   string
+	Message string
+}
+
+func (l *LimitError) Type() Type {
+	return LIMIT_ERROR_OBJECT
+}
+
+func (l *LimitError) Inspect() string {
+	return "LIMIT ERROR :" + l.Message
+}
+
 type Function struct {
 	Parameters  []*ast.Identifier
 	Body        *ast.BlockStatement
 	Environment *Environment
+	Slots       int
 }
 
 func (f *Function) Type() Type {
@@ -124,10 +141,17 @@ func (s *String) Inspect() string {
 	return s.Value
 }
 
-type BuiltinFunction func(args ...Object) Object
+type Runtime interface {
+	Apply(function Object, args ...Object) Object
+	Allocate(size int64) Object
+}
+
+type BuiltinFunction func(runtime Runtime, args ...Object) Object
 
 type Builtin struct {
 	Function BuiltinFunction
+	Arity    int
+	HasArity bool
 }
 
 func (b Builtin) Type() Type {
@@ -139,7 +163,57 @@ func (b Builtin) Inspect() string {
 }
 
 type Array struct {
-	Elements []Object
+	vector *vector
+	start  int
+	end    int
+}
+
+func NewArray(elements []Object) *Array {
+	return &Array{vector: newVector(elements), end: len(elements)}
+}
+
+func (a *Array) Len() int {
+	return a.end - a.start
+}
+
+func (a *Array) At(index int) Object {
+	return a.vector.get(a.start + index)
+}
+
+func (a *Array) Elements() []Object {
+	elements := make([]Object, 0, a.Len())
+	for index := a.start; index < a.end; {
+		leaf := a.vector.leaf(index)
+		from := index & VECTOR_MASK
+		to := len(leaf)
+		if remaining := a.end - index; to-from > remaining {
+			to = from + remaining
+		}
+		elements = append(elements, leaf[from:to]...)
+		index += to - from
+	}
+	return elements
+}
+
+func (a *Array) Push(o Object) *Array {
+	if a.vector == nil {
+		return NewArray([]Object{o})
+	}
+	return &Array{vector: a.vector.set(a.end, o), start: a.start, end: a.end + 1}
+}
+
+func (a *Array) Slice(from int, to int) *Array {
+	if from >= to {
+		return NewArray(nil)
+	}
+	return &Array{vector: a.vector, start: a.start + from, end: a.start + to}
+}
+
+func (a *Array) Rest() *Array {
+	if a.Len() == 0 {
+		return a
+	}
+	return a.Slice(1, a.Len())
 }
 
 func (a *Array) Type() Type {
@@ -150,13 +224,71 @@ func (a *Array) Inspect() string {
 	var out bytes.Buffer
 	var elements []string
 
-	for _, e := range a.Elements {
+	for _, e := range a.Elements() {
 		elements = append(elements, e.Inspect())
 	}
-	
+
 	out.WriteString("[")
 	out.WriteString(strings.Join(elements, ", "))
 	out.WriteString("]")
 
 	return out.String()
 }
+
+type HashKey struct {
+	Type  Type
+	Value uint64
+}
+
+type Hashable interface {
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+
+	if b.Value {
+		value = 1
+	}
+
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+type Hash struct {
+	Pairs map[HashKey]HashPair
+}
+
+func (h *Hash) Type() Type {
+	return HASH_OBJECT
+}
+
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+	var pairs []string
+
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair.Key.Inspect()+": "+pair.Value.Inspect())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
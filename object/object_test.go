@@ -0,0 +1,75 @@
+package object
+
+import "testing"
+
+func TestNewInteger(t *testing.T) {
+	tests := []struct {
+		value  int64
+		shared bool
+	}{
+		{0, true},
+		{SMALL_INTEGER_MIN, true},
+		{SMALL_INTEGER_MAX, true},
+		{SMALL_INTEGER_MIN - 1, false},
+		{SMALL_INTEGER_MAX + 1, false},
+	}
+
+	for _, tt := range tests {
+		first, second := NewInteger(tt.value), NewInteger(tt.value)
+		if first.Value != tt.value {
+			t.Errorf("NewInteger(%d).Value expected : %d, but was actual : %d", tt.value, tt.value, first.Value)
+		}
+		if (first == second) != tt.shared {
+			t.Errorf("NewInteger(%d) shared expected : %t, but was actual : %t", tt.value, tt.shared, first == second)
+		}
+	}
+}
+
+func TestInternString(t *testing.T) {
+	if InternString("monkey") != InternString("monkey") {
+		t.Errorf("interned strings expected to be shared")
+	}
+	if _, allocated := NewInternedString("monkey"); allocated {
+		t.Errorf("NewInternedString(monkey) expected to reuse the interned string")
+	}
+	if InternString("monkey") == InternString("gorilla") {
+		t.Errorf("different strings expected not to be shared")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	builtin := &Builtin{Function: func(runtime Runtime, args ...Object) Object { return nil }}
+	function := &Function{}
+	hash := func(value Object) *Hash {
+		key := &String{Value: "key"}
+		return &Hash{Pairs: map[HashKey]HashPair{key.HashKey(): {Key: key, Value: value}}}
+	}
+
+	tests := []struct {
+		left     Object
+		right    Object
+		expected bool
+	}{
+		{NewInteger(5000), NewInteger(5000), true},
+		{NewInteger(1), &String{Value: "1"}, false},
+		{&String{Value: "a"}, &String{Value: "a"}, true},
+		{&Boolean{Value: true}, &Boolean{Value: true}, true},
+		{&Null{}, &Null{}, true},
+		{NewArray([]Object{NewInteger(1), &String{Value: "a"}}), NewArray([]Object{NewInteger(1), &String{Value: "a"}}), true},
+		{NewArray([]Object{NewInteger(1)}), NewArray([]Object{NewInteger(2)}), false},
+		{hash(&Array{}), hash(&Array{}), true},
+		{hash(NewInteger(1)), hash(NewInteger(2)), false},
+		{function, function, true},
+		{function, &Function{}, false},
+		{builtin, builtin, true},
+		{builtin, &Builtin{Function: builtin.Function}, false},
+		{builtin, function, false},
+		{&Error{Message: "a"}, &Error{Message: "a"}, false},
+	}
+
+	for _, tt := range tests {
+		if actual := Equal(tt.left, tt.right); actual != tt.expected {
+			t.Errorf("Equal(%s, %s) expected : %t, but was actual : %t", tt.left.Inspect(), tt.right.Inspect(), tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package object
+
+const (
+	VECTOR_BITS  = 5
+	VECTOR_WIDTH = 1 << VECTOR_BITS
+	VECTOR_MASK  = VECTOR_WIDTH - 1
+)
+
+type vectorNode struct {
+	children []*vectorNode
+	values   []Object
+}
+
+type vector struct {
+	count int
+	shift uint
+	root  *vectorNode
+	tail  []Object
+}
+
+var emptyVector = &vector{shift: VECTOR_BITS, root: &vectorNode{}}
+
+func newVector(elements []Object) *vector {
+	v := emptyVector
+	for _, element := range elements {
+		v = v.push(element)
+	}
+	return v
+}
+
+func (v *vector) tailOffset() int {
+	if v.count < VECTOR_WIDTH {
+		return 0
+	}
+	return ((v.count - 1) >> VECTOR_BITS) << VECTOR_BITS
+}
+
+func (v *vector) leaf(index int) []Object {
+	if index >= v.tailOffset() {
+		return v.tail
+	}
+
+	node := v.root
+	for level := v.shift; level > 0; level -= VECTOR_BITS {
+		node = node.children[(index>>level)&VECTOR_MASK]
+	}
+	return node.values
+}
+
+func (v *vector) get(index int) Object {
+	return v.leaf(index)[index&VECTOR_MASK]
+}
+
+func (v *vector) push(o Object) *vector {
+	if v.count-v.tailOffset() < VECTOR_WIDTH {
+		tail := make([]Object, len(v.tail)+1, VECTOR_WIDTH)
+		copy(tail, v.tail)
+		tail[len(v.tail)] = o
+		return &vector{count: v.count + 1, shift: v.shift, root: v.root, tail: tail}
+	}
+
+	leaf := &vectorNode{values: v.tail}
+	shift := v.shift
+
+	var root *vectorNode
+	if v.count>>VECTOR_BITS > 1<<v.shift {
+		root = &vectorNode{children: []*vectorNode{v.root, newPath(v.shift, leaf)}}
+		shift += VECTOR_BITS
+	} else {
+		root = v.pushLeaf(v.shift, v.root, leaf)
+	}
+
+	tail := make([]Object, 1, VECTOR_WIDTH)
+	tail[0] = o
+	return &vector{count: v.count + 1, shift: shift, root: root, tail: tail}
+}
+
+func (v *vector) pushLeaf(level uint, parent *vectorNode, leaf *vectorNode) *vectorNode {
+	index := ((v.count - 1) >> level) & VECTOR_MASK
+	node := &vectorNode{children: append([]*vectorNode(nil), parent.children...)}
+
+	child := leaf
+	if level > VECTOR_BITS {
+		if index < len(parent.children) {
+			child = v.pushLeaf(level-VECTOR_BITS, parent.children[index], leaf)
+		} else {
+			child = newPath(level-VECTOR_BITS, leaf)
+		}
+	}
+
+	if index < len(node.children) {
+		node.children[index] = child
+	} else {
+		node.children = append(node.children, child)
+	}
+	return node
+}
+
+func newPath(level uint, leaf *vectorNode) *vectorNode {
+	if level == 0 {
+		return leaf
+	}
+	return &vectorNode{children: []*vectorNode{newPath(level-VECTOR_BITS, leaf)}}
+}
+
+func (v *vector) set(index int, o Object) *vector {
+	if index == v.count {
+		return v.push(o)
+	}
+
+	if index >= v.tailOffset() {
+		tail := make([]Object, len(v.tail), VECTOR_WIDTH)
+		copy(tail, v.tail)
+		tail[index&VECTOR_MASK] = o
+		return &vector{count: v.count, shift: v.shift, root: v.root, tail: tail}
+	}
+
+	return &vector{count: v.count, shift: v.shift, root: setPath(v.shift, v.root, index, o), tail: v.tail}
+}
+
+func setPath(level uint, node *vectorNode, index int, o Object) *vectorNode {
+	if level == 0 {
+		values := append([]Object(nil), node.values...)
+		values[index&VECTOR_MASK] = o
+		return &vectorNode{values: values}
+	}
+
+	children := append([]*vectorNode(nil), node.children...)
+	child := (index >> level) & VECTOR_MASK
+	children[child] = setPath(level-VECTOR_BITS, node.children[child], index, o)
+	return &vectorNode{children: children}
+}
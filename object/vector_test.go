@@ -0,0 +1,93 @@
+package object
+
+import "testing"
+
+func TestArrayPush(t *testing.T) {
+	sizes := []int{0, 1, VECTOR_WIDTH, VECTOR_WIDTH + 1, VECTOR_WIDTH*VECTOR_WIDTH + VECTOR_WIDTH + 1, 40000}
+
+	for _, size := range sizes {
+		array := NewArray(nil)
+		versions := make([]*Array, 0, size+1)
+		for i := 0; i < size; i++ {
+			versions = append(versions, array)
+			array = array.Push(NewInteger(int64(i)))
+		}
+
+		if array.Len() != size {
+			t.Fatalf("len expected : %d, but was actual : %d", size, array.Len())
+		}
+		for i, element := range array.Elements() {
+			if element.(*Integer).Value != int64(i) || array.At(i).(*Integer).Value != int64(i) {
+				t.Fatalf("element %d of %d expected : %d, but was actual : %s", i, size, i, element.Inspect())
+			}
+		}
+		for i, version := range versions {
+			if version.Len() != i {
+				t.Fatalf("version %d len expected : %d, but was actual : %d", i, i, version.Len())
+			}
+		}
+	}
+}
+
+func TestArraySlice(t *testing.T) {
+	elements := make([]Object, 100)
+	for i := range elements {
+		elements[i] = NewInteger(int64(i))
+	}
+	array := NewArray(elements)
+
+	tests := []struct {
+		array    *Array
+		expected []int64
+	}{
+		{array.Slice(10, 13), []int64{10, 11, 12}},
+		{array.Slice(5, 5), []int64{}},
+		{array.Slice(30, 34).Rest(), []int64{31, 32, 33}},
+		{array.Slice(30, 34).Push(NewInteger(-1)), []int64{30, 31, 32, 33, -1}},
+		{array.Slice(98, 100).Push(NewInteger(-2)), []int64{98, 99, -2}},
+		{array.Rest().Rest().Slice(0, 2), []int64{2, 3}},
+		{NewArray(nil).Rest(), []int64{}},
+	}
+
+	for i, tt := range tests {
+		actual := tt.array.Elements()
+		if len(actual) != len(tt.expected) {
+			t.Errorf("tests[%d] len expected : %d, but was actual : %d", i, len(tt.expected), len(actual))
+			continue
+		}
+		for j, element := range actual {
+			if element.(*Integer).Value != tt.expected[j] {
+				t.Errorf("tests[%d] element %d expected : %d, but was actual : %s", i, j, tt.expected[j], element.Inspect())
+			}
+		}
+	}
+
+	if array.Len() != 100 || array.At(34).(*Integer).Value != 34 || array.At(99).(*Integer).Value != 99 {
+		t.Errorf("original array expected to be unchanged, but was actual : %s", array.Inspect())
+	}
+}
+
+func BenchmarkArrayPush(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		array := NewArray(nil)
+		for j := 0; j < 1000; j++ {
+			array = array.Push(NewInteger(int64(j)))
+		}
+	}
+}
+
+func BenchmarkCopyingPush(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var elements []Object
+		for j := 0; j < 1000; j++ {
+			pushed := make([]Object, len(elements)+1)
+			copy(pushed, elements)
+			pushed[len(elements)] = NewInteger(int64(j))
+			elements = pushed
+		}
+	}
+}
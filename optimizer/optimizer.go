@@ -0,0 +1,390 @@
+package optimizer
+
+import (
+	"monkey/analysis"
+	"monkey/ast"
+	"monkey/token"
+	"strconv"
+)
+
+type optimizer struct {
+	inline  map[*ast.Identifier]ast.Expression
+	removed map[*ast.LetStatement]bool
+}
+
+func Optimize(program *ast.Program) *ast.Program {
+	o := &optimizer{
+		inline:  make(map[*ast.Identifier]ast.Expression),
+		removed: make(map[*ast.LetStatement]bool),
+	}
+
+	for {
+		program.Statements = o.statements(program.Statements)
+		if !o.inlineConstants(program) {
+			return program
+		}
+	}
+}
+
+func (o *optimizer) statements(statements []ast.Statement) []ast.Statement {
+	result := make([]ast.Statement, 0, len(statements))
+
+	for i, statement := range statements {
+		last := i == len(statements)-1
+
+		if let, ok := statement.(*ast.LetStatement); ok && o.removed[let] && !last {
+			continue
+		}
+
+		statement = o.statement(statement)
+
+		if branch, ok := deadBranch(statement); ok && (!last || endsWithValue(branch)) {
+			if branch != nil {
+				result = append(result, branch.Statements...)
+			}
+			continue
+		}
+
+		result = append(result, statement)
+	}
+
+	return result
+}
+
+func deadBranch(statement ast.Statement) (*ast.BlockStatement, bool) {
+	expression, ok := statement.(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+
+	ifExpression, ok := expression.Expression.(*ast.IfExpression)
+	if !ok {
+		return nil, false
+	}
+
+	truthy, ok := constantTruthiness(ifExpression.Condition)
+	if !ok {
+		return nil, false
+	}
+
+	if truthy {
+		return ifExpression.Consequence, true
+	}
+	return ifExpression.Alternative, true
+}
+
+func endsWithValue(block *ast.BlockStatement) bool {
+	if block == nil || len(block.Statements) == 0 {
+		return false
+	}
+
+	switch block.Statements[len(block.Statements)-1].(type) {
+	case *ast.ExpressionStatement, *ast.ReturnStatement:
+		return true
+	default:
+		return false
+	}
+}
+
+func (o *optimizer) statement(statement ast.Statement) ast.Statement {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		statement.Value = o.expression(statement.Value)
+	case *ast.ReturnStatement:
+		statement.ReturnValue = o.expression(statement.ReturnValue)
+	case *ast.ExpressionStatement:
+		statement.Expression = o.expression(statement.Expression)
+	case *ast.BlockStatement:
+		o.block(statement)
+	}
+	return statement
+}
+
+func (o *optimizer) block(block *ast.BlockStatement) {
+	if block != nil {
+		block.Statements = o.statements(block.Statements)
+	}
+}
+
+func (o *optimizer) expressions(expressions []ast.Expression) {
+	for i, expression := range expressions {
+		expressions[i] = o.expression(expression)
+	}
+}
+
+func (o *optimizer) expression(expression ast.Expression) ast.Expression {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		if value, ok := o.inline[expression]; ok {
+			return copyLiteral(value, expression.Token.Position)
+		}
+	case *ast.PrefixExpression:
+		expression.Right = o.expression(expression.Right)
+		if folded := foldPrefix(expression); folded != nil {
+			return folded
+		}
+	case *ast.InfixExpression:
+		expression.Left = o.expression(expression.Left)
+		expression.Right = o.expression(expression.Right)
+		if folded := foldInfix(expression); folded != nil {
+			return folded
+		}
+	case *ast.IfExpression:
+		expression.Condition = o.expression(expression.Condition)
+		o.block(expression.Consequence)
+		o.block(expression.Alternative)
+		return foldIf(expression)
+	case *ast.FunctionLiteral:
+		o.block(expression.Body)
+	case *ast.CallExpression:
+		expression.Function = o.expression(expression.Function)
+		o.expressions(expression.Arguments)
+	case *ast.ArrayLiteral:
+		o.expressions(expression.Elements)
+	case *ast.IndexExpression:
+		expression.Left = o.expression(expression.Left)
+		expression.Index = o.expression(expression.Index)
+	case *ast.SliceExpression:
+		expression.Left = o.expression(expression.Left)
+		if expression.Start != nil {
+			expression.Start = o.expression(expression.Start)
+		}
+		if expression.End != nil {
+			expression.End = o.expression(expression.End)
+		}
+	case *ast.HashLiteral:
+		for _, pair := range expression.Pairs {
+			pair.Key = o.expression(pair.Key)
+			pair.Value = o.expression(pair.Value)
+		}
+	case *ast.MatchExpression:
+		expression.Subject = o.expression(expression.Subject)
+		for _, arm := range expression.Arms {
+			if arm.Guard != nil {
+				arm.Guard = o.expression(arm.Guard)
+			}
+			o.block(arm.Body)
+		}
+	}
+	return expression
+}
+
+func foldPrefix(expression *ast.PrefixExpression) ast.Expression {
+	position := expression.Token.Position
+
+	switch expression.Operator {
+	case "!":
+		if truthy, ok := constantTruthiness(expression.Right); ok {
+			return newBoolean(!truthy, position)
+		}
+	case "-":
+		if number, ok := expression.Right.(*ast.NumberLiteral); ok {
+			return newNumber(-number.Value, position)
+		}
+	}
+	return nil
+}
+
+func foldInfix(expression *ast.InfixExpression) ast.Expression {
+	switch left := expression.Left.(type) {
+	case *ast.NumberLiteral:
+		if right, ok := expression.Right.(*ast.NumberLiteral); ok {
+			return foldNumbers(expression.Operator, left, right)
+		}
+	case *ast.StringLiteral:
+		if right, ok := expression.Right.(*ast.StringLiteral); ok {
+			switch expression.Operator {
+			case "+":
+				return newString(left.Value+right.Value, left.Token.Position)
+			case "==":
+				return newBoolean(left.Value == right.Value, left.Token.Position)
+			case "!=":
+				return newBoolean(left.Value != right.Value, left.Token.Position)
+			}
+			return nil
+		}
+	case *ast.Boolean:
+		if right, ok := expression.Right.(*ast.Boolean); ok {
+			switch expression.Operator {
+			case "==":
+				return newBoolean(left.Value == right.Value, left.Token.Position)
+			case "!=":
+				return newBoolean(left.Value != right.Value, left.Token.Position)
+			}
+			return nil
+		}
+	}
+
+	if isConstant(expression.Left) && isConstant(expression.Right) {
+		position := expression.Token.Position
+		switch expression.Operator {
+		case "==":
+			return newBoolean(false, position)
+		case "!=":
+			return newBoolean(true, position)
+		}
+	}
+
+	return nil
+}
+
+func foldNumbers(operator string, left *ast.NumberLiteral, right *ast.NumberLiteral) ast.Expression {
+	position := left.Token.Position
+
+	switch operator {
+	case "+":
+		return newNumber(left.Value+right.Value, position)
+	case "-":
+		return newNumber(left.Value-right.Value, position)
+	case "*":
+		return newNumber(left.Value*right.Value, position)
+	case "/":
+		if right.Value == 0 {
+			return nil
+		}
+		return newNumber(left.Value/right.Value, position)
+	case "<":
+		return newBoolean(left.Value < right.Value, position)
+	case ">":
+		return newBoolean(left.Value > right.Value, position)
+	case "==":
+		return newBoolean(left.Value == right.Value, position)
+	case "!=":
+		return newBoolean(left.Value != right.Value, position)
+	default:
+		return nil
+	}
+}
+
+func foldIf(expression *ast.IfExpression) ast.Expression {
+	truthy, ok := constantTruthiness(expression.Condition)
+	if !ok {
+		return expression
+	}
+
+	branch := expression.Alternative
+	if truthy {
+		branch = expression.Consequence
+	}
+
+	if branch != nil && len(branch.Statements) == 1 {
+		if statement, ok := branch.Statements[0].(*ast.ExpressionStatement); ok && statement.Expression != nil {
+			return statement.Expression
+		}
+	}
+
+	if !truthy && expression.Consequence != nil {
+		expression.Consequence = &ast.BlockStatement{Token: expression.Consequence.Token}
+	} else if truthy {
+		expression.Alternative = nil
+	}
+	return expression
+}
+
+func (o *optimizer) inlineConstants(program *ast.Program) bool {
+	result := analysis.Analyze(program)
+
+	declarations := make(map[string]int)
+	for _, symbol := range result.Symbols {
+		declarations[symbol.Name]++
+	}
+
+	straight := make(map[*ast.LetStatement]bool)
+	global := make(map[*ast.LetStatement]bool)
+	for _, statement := range program.Statements {
+		if let, ok := statement.(*ast.LetStatement); ok {
+			straight[let] = true
+			global[let] = true
+		}
+	}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if function, ok := node.(*ast.FunctionLiteral); ok && function.Body != nil {
+			for _, statement := range function.Body.Statements {
+				if let, ok := statement.(*ast.LetStatement); ok {
+					straight[let] = true
+				}
+			}
+		}
+		return true
+	})
+
+	changed := false
+	for _, symbol := range result.Symbols {
+		statement := symbol.Statement
+		if symbol.Kind != analysis.LET && symbol.Kind != analysis.CONST {
+			continue
+		}
+		if statement.Pattern != nil || !straight[statement] || declarations[symbol.Name] != 1 || !isConstant(statement.Value) {
+			continue
+		}
+		if global[statement] && symbol.Kind != analysis.CONST {
+			continue
+		}
+
+		inlined := 0
+		for _, reference := range symbol.References {
+			if reference.Token.Position.Offset > statement.Token.Position.Offset {
+				o.inline[reference] = statement.Value
+				inlined++
+			}
+		}
+
+		if inlined > 0 {
+			changed = true
+		}
+		if inlined == len(symbol.References) && !global[statement] && !o.removed[statement] {
+			o.removed[statement] = true
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func isConstant(expression ast.Expression) bool {
+	switch expression.(type) {
+	case *ast.NumberLiteral, *ast.StringLiteral, *ast.Boolean:
+		return true
+	default:
+		return false
+	}
+}
+
+func constantTruthiness(expression ast.Expression) (bool, bool) {
+	switch expression := expression.(type) {
+	case *ast.Boolean:
+		return expression.Value, true
+	case *ast.NumberLiteral, *ast.StringLiteral:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func copyLiteral(expression ast.Expression, position token.Position) ast.Expression {
+	switch expression := expression.(type) {
+	case *ast.NumberLiteral:
+		return newNumber(expression.Value, position)
+	case *ast.StringLiteral:
+		return newString(expression.Value, position)
+	case *ast.Boolean:
+		return newBoolean(expression.Value, position)
+	default:
+		return expression
+	}
+}
+
+func newNumber(value int64, position token.Position) *ast.NumberLiteral {
+	return &ast.NumberLiteral{Token: token.At(token.NUMBER, strconv.FormatInt(value, 10), position), Value: value}
+}
+
+func newString(value string, position token.Position) *ast.StringLiteral {
+	return &ast.StringLiteral{Token: token.At(token.STRING, value, position), Value: value}
+}
+
+func newBoolean(value bool, position token.Position) *ast.Boolean {
+	if value {
+		return &ast.Boolean{Token: token.At(token.TRUE, "true", position), Value: true}
+	}
+	return &ast.Boolean{Token: token.At(token.FALSE, "false", position), Value: false}
+}
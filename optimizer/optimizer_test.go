@@ -0,0 +1,88 @@
+package optimizer
+
+import (
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors of %q : %v", input, p.Errors())
+	}
+	return program
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"60 * 60 * 24", "86400"},
+		{"1 + 2 * 3 - 4 / 2", "5"},
+		{"-(2 + 3)", "-5"},
+		{"x * (2 + 3)", "(x * 5)"},
+		{"1 / 0", "(1 / 0)"},
+		{`"foo" + "bar" + "baz"`, "foobarbaz"},
+		{`"a" == "a"`, "true"},
+		{`"a" != "a"`, "false"},
+		{`"a" - "a"`, "(a - a)"},
+		{"!true == false", "true"},
+		{"1 < 2 != (3 > 4)", "true"},
+		{`1 == "1"`, "false"},
+		{"!5", "false"},
+		{"if (1 > 2) { a } else { b }", "b"},
+		{"if (true) { a }", "a"},
+		{"if (false) { a }", "iffalse"},
+		{"if (true) { let y = 1; y } else { z }; 1", "let y = 1;y1"},
+		{"if (false) { a }; b", "b"},
+		{"if (x) { 1 + 1 } else { 2 * 2 }", "ifx2else4"},
+		{"const day = 60 * 60 * 24; day * 7", "const day = 86400;604800"},
+		{"const a = 2; const b = a * 3; b + a", "const a = 2;const b = 6;8"},
+		{"let a = 2; a", "let a = 2;a"},
+		{"let f = fn(x) { let scale = 10; x * scale }", "let f = fn(x) (x * 10);"},
+		{"let f = fn(x) { let scale = 10; scale }", "let f = fn(x) 10;"},
+		{"let f = fn(x) { let scale = 10 }", "let f = fn(x) let scale = 10;;"},
+		{"let f = fn(x) { let scale = 10; let scale = 20; scale }", "let f = fn(x) let scale = 10;let scale = 20;scale;"},
+		{"let f = fn(x) { let g = fn() { n }; let n = 1; g() + n }", "let f = fn(x) let g = fn() n;let n = 1;(g() + 1);"},
+		{"let f = fn(n) { if (n) { let k = 1; k } }", "let f = fn(n) ifnlet k = 1;k;"},
+		{"let g = fn(n) { n }; let f = fn(x) { let n = 1; n }", "let g = fn(n) n;let f = fn(x) let n = 1;n;"},
+		{"match (1 + 1) { 2 if 1 < 2 => [3 * 3] }", "match (2) { 2 if true => [9] }"},
+	}
+
+	for _, tt := range tests {
+		program := Optimize(parse(t, tt.input))
+		if program.String() != tt.expected {
+			t.Errorf("optimized %q expected : %q, but was actual : %q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestOptimizePreservesResults(t *testing.T) {
+	tests := []string{
+		"60 * 60 * 24 * 7",
+		`let greet = fn(name) { "hello, " + name + "!" }; greet("monkey")`,
+		"const limit = 10; let f = fn(n) { if (n > limit) { n } else { f(n + limit / 2) } }; f(1)",
+		"let f = fn() { if (true) { return 1; } 2 }; f()",
+		"let f = fn() { if (false) { 1 } }; f()",
+		"let f = fn(x) { let k = 3; let g = fn(y) { x * k + y }; g(k) }; f(2)",
+		"if (1 == 1) { let z = 5; }; z",
+		"match ([1, 2]) { [a, b] if a + b == 3 => a * 10 + b, _ => 0 }",
+		"const s = \"ab\"; len(s + s) == 4",
+		`"ab" == "a" + "b"`,
+	}
+
+	for _, input := range tests {
+		expected := evaluator.Evaluate(parse(t, input), object.NewEnvironment())
+		actual := evaluator.Evaluate(Optimize(parse(t, input)), object.NewEnvironment())
+
+		if expected.Inspect() != actual.Inspect() {
+			t.Errorf("result of %q expected : %s, but was actual : %s", input, expected.Inspect(), actual.Inspect())
+		}
+	}
+}
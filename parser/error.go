@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"monkey/lexer"
+	"monkey/token"
+	"strconv"
+	"strings"
+)
+
+type Error struct {
+	Position token.Position
+	Message  string
+	Expected token.Type
+	Actual   token.Type
+}
+
+func (e Error) Error() string {
+	return e.Position.String() + " : " + e.Message
+}
+
+func (e Error) Describe(source string) string {
+	offset := e.Position.Offset
+	if offset > len(source) {
+		offset = len(source)
+	}
+
+	start := strings.LastIndexByte(source[:offset], '\n') + 1
+	end := strings.IndexByte(source[offset:], '\n')
+	if end < 0 {
+		end = len(source)
+	} else {
+		end += offset
+	}
+
+	return e.DescribeLine(strings.TrimRight(source[start:end], "\r"))
+}
+
+func (e Error) DescribeLine(line string) string {
+	column := e.Position.Column - 1
+	if column < 0 || column > len(line) {
+		return e.Error()
+	}
+
+	var caret strings.Builder
+	for _, r := range line[:column] {
+		if r == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	caret.WriteRune('^')
+
+	number := strconv.Itoa(e.Position.Line)
+	gutter := strings.Repeat(" ", len(number))
+
+	return e.Error() + "\n" +
+		" " + number + " | " + line + "\n" +
+		" " + gutter + " | " + caret.String()
+}
+
+const EXCERPT_SIZE = 4 * lexer.BUFFER_SIZE
+
+type excerptLine struct {
+	number int
+	text   string
+}
+
+type ExcerptReader struct {
+	reader   io.Reader
+	parser   *Parser
+	reported int
+	line     int
+	partial  []byte
+	long     bool
+	recent   []excerptLine
+	size     int
+	wanted   map[int]bool
+	lines    map[int]string
+}
+
+func NewExcerptReader(reader io.Reader) *ExcerptReader {
+	return &ExcerptReader{
+		reader: reader,
+		line:   1,
+		wanted: make(map[int]bool),
+		lines:  make(map[int]string),
+	}
+}
+
+func (r *ExcerptReader) Track(p *Parser) {
+	r.parser = p
+}
+
+func (r *ExcerptReader) Read(b []byte) (int, error) {
+	r.collect()
+
+	n, err := r.reader.Read(b)
+	r.record(b[:n])
+	if err != nil {
+		r.complete()
+	}
+	return n, err
+}
+
+func (r *ExcerptReader) Lines() map[int]string {
+	r.collect()
+	return r.lines
+}
+
+func (r *ExcerptReader) collect() {
+	if r.parser == nil {
+		return
+	}
+
+	errors := r.parser.Diagnostics()
+	for _, err := range errors[r.reported:] {
+		number := err.Position.Line
+		if number == r.line {
+			r.wanted[number] = true
+			continue
+		}
+		for _, line := range r.recent {
+			if line.number == number {
+				r.lines[number] = line.text
+			}
+		}
+	}
+	r.reported = len(errors)
+}
+
+func (r *ExcerptReader) record(data []byte) {
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.append(data)
+			return
+		}
+
+		r.append(data[:i])
+		r.complete()
+		data = data[i+1:]
+	}
+}
+
+func (r *ExcerptReader) append(data []byte) {
+	if r.long || len(r.partial)+len(data) > EXCERPT_SIZE {
+		r.long = true
+		r.partial = r.partial[:0]
+		return
+	}
+	r.partial = append(r.partial, data...)
+}
+
+func (r *ExcerptReader) complete() {
+	if !r.long {
+		text := strings.TrimRight(string(r.partial), "\r")
+		r.recent = append(r.recent, excerptLine{number: r.line, text: text})
+		r.size += len(text) + 1
+		for r.size > EXCERPT_SIZE {
+			r.size -= len(r.recent[0].text) + 1
+			r.recent = r.recent[1:]
+		}
+
+		if r.wanted[r.line] {
+			r.lines[r.line] = text
+		}
+	}
+
+	delete(r.wanted, r.line)
+	r.line++
+	r.partial = r.partial[:0]
+	r.long = false
+}
@@ -44,14 +44,17 @@ type Parser struct {
 	currentToken token.Token
 	peekToken    token.Token
 
-	errors []string
+	errors       []Error
+	synchronized int
+
+	scopes []map[string]bool
 
 	prefixParseFunctions map[token.Type]prefixParseFunction
 	infixParseFunctions  map[token.Type]infixParseFunction
 }
 
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
+	p := &Parser{l: l, errors: []Error{}, scopes: []map[string]bool{{}}}
 	p.nextToken()
 	p.nextToken()
 
@@ -67,6 +70,8 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 
 	p.infixParseFunctions = make(map[token.Type]infixParseFunction)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -97,6 +102,7 @@ func (p *Parser) ParseProgram() *ast.Program {
 		if statement != nil {
 			program.Statements = append(program.Statements, statement)
 		}
+		p.recover(token.EOF)
 		p.nextToken()
 	}
 
@@ -105,23 +111,47 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currentToken.Type {
-	case token.LET:
-		return p.parseLetStatement()
+	case token.LET, token.CONST:
+		if statement := p.parseLetStatement(); statement != nil {
+			return statement
+		}
 	case token.RETURN:
-		return p.parseReturnStatement()
+		if statement := p.parseReturnStatement(); statement != nil {
+			return statement
+		}
 	default:
-		return p.parseExpressionStatement()
+		if statement := p.parseExpressionStatement(); statement != nil {
+			return statement
+		}
 	}
+	return nil
 }
 
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	statement := &ast.LetStatement{Token: p.currentToken}
 
-	if !p.expectPeek(token.ID) {
-		return nil
-	}
+	switch {
+	case p.peekTokenIs(token.LBRACKET):
+		p.nextToken()
+		pattern := p.parseArrayPattern()
+		if pattern == nil {
+			return nil
+		}
+		statement.Pattern = pattern
+	case p.peekTokenIs(token.LBRACE):
+		p.nextToken()
+		pattern := p.parseHashPattern()
+		if pattern == nil {
+			return nil
+		}
+		statement.Pattern = pattern
+	default:
+		if !p.expectPeek(token.ID) {
+			return nil
+		}
 
-	statement.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		statement.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	}
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
@@ -135,9 +165,175 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		p.nextToken()
 	}
 
+	p.declareBindings(statement)
+
 	return statement
 }
 
+func (p *Parser) declareBindings(statement *ast.LetStatement) {
+	names := []*ast.Identifier{statement.Name}
+	if statement.Pattern != nil {
+		names = ast.PatternNames(statement.Pattern)
+	}
+
+	scope := p.scopes[len(p.scopes)-1]
+	for _, name := range names {
+		if scope[name.Value] {
+			p.errorAt(name.Token.Position, "cannot redefine constant %s", name.Value)
+		}
+		scope[name.Value] = scope[name.Value] || statement.Token.Type == token.CONST
+	}
+}
+
+func (p *Parser) parsePattern() ast.Pattern {
+	pattern := p.parseSinglePattern()
+	if pattern == nil || !p.peekTokenIs(token.PIPE) {
+		return pattern
+	}
+
+	alternative := &ast.AlternativePattern{Token: p.peekToken, Alternatives: []ast.Pattern{pattern}}
+
+	for p.peekTokenIs(token.PIPE) {
+		p.nextToken()
+		p.nextToken()
+
+		pattern := p.parseSinglePattern()
+		if pattern == nil {
+			return nil
+		}
+		alternative.Alternatives = append(alternative.Alternatives, pattern)
+	}
+
+	return alternative
+}
+
+func (p *Parser) parseSinglePattern() ast.Pattern {
+	switch p.currentToken.Type {
+	case token.ID:
+		return &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	case token.LBRACKET:
+		if pattern := p.parseArrayPattern(); pattern != nil {
+			return pattern
+		}
+		return nil
+	case token.LBRACE:
+		if pattern := p.parseHashPattern(); pattern != nil {
+			return pattern
+		}
+		return nil
+	case token.NUMBER:
+		return p.parseLiteralPattern(p.parseNumberLiteral)
+	case token.STRING:
+		return p.parseLiteralPattern(p.parseStringLiteral)
+	case token.TRUE, token.FALSE:
+		return p.parseLiteralPattern(p.parseBoolean)
+	case token.MINUS:
+		if !p.peekTokenIs(token.NUMBER) {
+			p.peekError(token.NUMBER)
+			return nil
+		}
+		return p.parseLiteralPattern(p.parsePrefixExpression)
+	default:
+		p.errors = append(p.errors, Error{
+			Position: p.currentToken.Position,
+			Message:  fmt.Sprintf("unexpected token in pattern : %s", p.currentToken.Type),
+			Actual:   p.currentToken.Type,
+		})
+		return nil
+	}
+}
+
+func (p *Parser) parseLiteralPattern(parse prefixParseFunction) ast.Pattern {
+	pattern := &ast.LiteralPattern{Token: p.currentToken}
+
+	pattern.Value = parse()
+	if pattern.Value == nil {
+		return nil
+	}
+
+	return pattern
+}
+
+func (p *Parser) parseArrayPattern() *ast.ArrayPattern {
+	pattern := &ast.ArrayPattern{Token: p.currentToken}
+
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		return pattern
+	}
+
+	for {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if !p.expectPeek(token.ID) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+			break
+		}
+
+		p.nextToken()
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return pattern
+}
+
+func (p *Parser) parseHashPattern() *ast.HashPattern {
+	pattern := &ast.HashPattern{Token: p.currentToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		if !p.currentTokenIs(token.ID) && !p.currentTokenIs(token.STRING) {
+			p.errors = append(p.errors, Error{
+				Position: p.currentToken.Position,
+				Message:  fmt.Sprintf("unexpected token in hash pattern : %s", p.currentToken.Type),
+				Actual:   p.currentToken.Type,
+			})
+			return nil
+		}
+
+		pair := &ast.HashPatternPair{Key: &ast.StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}}
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			pair.Value = p.parsePattern()
+			if pair.Value == nil {
+				return nil
+			}
+		} else if p.currentTokenIs(token.ID) {
+			pair.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		} else {
+			p.peekError(token.COLON)
+			return nil
+		}
+		pattern.Pairs = append(pattern.Pairs, pair)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return pattern
+}
+
 func (p *Parser) parseReturnStatement() ast.Statement {
 	statement := &ast.ReturnStatement{Token: p.currentToken}
 	p.nextToken()
@@ -193,8 +389,7 @@ func (p *Parser) parseNumberLiteral() ast.Expression {
 
 	value, err := strconv.ParseInt(p.currentToken.Literal, 10, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as number", p.currentToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.currentToken.Position, "could not parse %q as number", p.currentToken.Literal)
 		return nil
 	}
 
@@ -277,17 +472,68 @@ func (p *Parser) registerInfix(tokenType token.Type, function infixParseFunction
 }
 
 func (p *Parser) Errors() []string {
+	messages := make([]string, len(p.errors))
+	for i, err := range p.errors {
+		messages[i] = err.Message
+	}
+	return messages
+}
+
+func (p *Parser) Diagnostics() []Error {
 	return p.errors
 }
 
+func (p *Parser) errorAt(position token.Position, format string, a ...interface{}) {
+	p.errors = append(p.errors, Error{Position: position, Message: fmt.Sprintf(format, a...)})
+}
+
 func (p *Parser) peekError(t token.Type) {
-	message := fmt.Sprintf("next token expected : %s, but was actual : %s", t, p.peekToken.Type)
-	p.errors = append(p.errors, message)
+	p.errors = append(p.errors, Error{
+		Position: p.peekToken.Position,
+		Message:  fmt.Sprintf("next token expected : %s, but was actual : %s", t, p.peekToken.Type),
+		Expected: t,
+		Actual:   p.peekToken.Type,
+	})
 }
 
 func (p *Parser) noPrefixParseFunctionError(t token.Type) {
-	message := fmt.Sprintf("no prefix parse function for %s", t)
-	p.errors = append(p.errors, message)
+	p.errors = append(p.errors, Error{
+		Position: p.currentToken.Position,
+		Message:  fmt.Sprintf("no prefix parse function for %s", t),
+		Actual:   t,
+	})
+}
+
+func (p *Parser) recover(end token.Type) {
+	if len(p.errors) == p.synchronized {
+		return
+	}
+	p.synchronized = len(p.errors)
+
+	depth := 0
+	for !p.currentTokenIs(token.EOF) {
+		switch p.currentToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			if depth > 0 {
+				depth--
+			}
+		case token.SEMICOLON:
+			if depth == 0 {
+				return
+			}
+		}
+
+		if depth == 0 && (p.peekTokenIs(end) || p.peekTokenIs(token.EOF) || isStatementStart(p.peekToken.Type)) {
+			return
+		}
+		p.nextToken()
+	}
+}
+
+func isStatementStart(t token.Type) bool {
+	return t == token.LET || t == token.CONST || t == token.RETURN
 }
 
 func (p *Parser) parseIfExpression() ast.Expression {
@@ -333,6 +579,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		if statement != nil {
 			block.Statements = append(block.Statements, statement)
 		}
+		p.recover(token.RBRACE)
 		p.nextToken()
 	}
 
@@ -352,7 +599,9 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
+	p.scopes = append(p.scopes, map[string]bool{})
 	function.Body = p.parseBlockStatement()
+	p.scopes = p.scopes[:len(p.scopes)-1]
 
 	return function
 }
@@ -425,11 +674,64 @@ func (p *Parser) parseExpressionList(end token.Type) []ast.Expression {
 	return list
 }
 
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.currentToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, &ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	expression := &ast.IndexExpression{Token: p.currentToken, Left: left}
+	current := p.currentToken
+
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(current, left, nil)
+	}
 
 	p.nextToken()
-	expression.Index = p.parseExpression(LOWEST)
+	index := p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(current, left, index)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return &ast.IndexExpression{Token: current, Left: left, Index: index}
+}
+
+func (p *Parser) parseSliceExpression(current token.Token, left ast.Expression, start ast.Expression) ast.Expression {
+	expression := &ast.SliceExpression{Token: current, Left: left, Start: start}
+
+	p.nextToken()
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		expression.End = p.parseExpression(LOWEST)
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
@@ -437,3 +739,75 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 
 	return expression
 }
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{Token: p.currentToken}
+
+	arm.Pattern = p.parsePattern()
+	if arm.Pattern == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		arm.Guard = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		arm.Body = p.parseBlockStatement()
+		return arm
+	}
+
+	p.nextToken()
+	body := &ast.ExpressionStatement{Token: p.currentToken}
+	body.Expression = p.parseExpression(LOWEST)
+	arm.Body = &ast.BlockStatement{Token: body.Token, Statements: []ast.Statement{body}}
+
+	return arm
+}
@@ -1,10 +1,15 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"monkey/ast"
 	"monkey/lexer"
+	"monkey/token"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestLetStatements(t *testing.T) {
@@ -66,6 +71,184 @@ func testLetStatement(t *testing.T, s ast.Statement, expected string) bool {
 	return true
 }
 
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		elements []string
+		rest     string
+		expected string
+	}{
+		{"let [a, b] = pair;", []string{"a", "b"}, "", "let [a, b] = pair;"},
+		{"let [a, b, ...rest] = [1, 2, 3];", []string{"a", "b"}, "rest", "let [a, b, ...rest] = [1, 2, 3];"},
+		{"let [...all] = list;", nil, "all", "let [...all] = list;"},
+		{"let [] = list;", nil, "", "let [] = list;"},
+		{"let [a, [b, _]] = list;", []string{"a", "[b, _]"}, "", "let [a, [b, _]] = list;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+		checkProgramLength(t, 1, program)
+
+		statement, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("statement expected : ast.LetStatement, but was actual : %T", program.Statements[0])
+		}
+
+		pattern, ok := statement.Pattern.(*ast.ArrayPattern)
+		if !ok {
+			t.Fatalf("statement.Pattern expected : *ast.ArrayPattern, but was actual : %T", statement.Pattern)
+		}
+
+		if len(pattern.Elements) != len(tt.elements) {
+			t.Fatalf("len(pattern.Elements) expected : %d, but was actual : %d", len(tt.elements), len(pattern.Elements))
+		}
+
+		for i, name := range tt.elements {
+			if pattern.Elements[i].String() != name {
+				t.Errorf("pattern.Elements[%d] expected : %s, but was actual : %s", i, name, pattern.Elements[i])
+			}
+		}
+
+		if tt.rest == "" && pattern.Rest != nil {
+			t.Errorf("pattern.Rest expected nil, but was actual : %s", pattern.Rest)
+		}
+
+		if tt.rest != "" {
+			testIdentifier(t, pattern.Rest, tt.rest)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("program expected : %s, but was actual : %s", tt.expected, program.String())
+		}
+	}
+}
+
+func TestHashPatternLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		names    []string
+	}{
+		{"let {name, age} = person;", "let {name, age} = person;", []string{"name", "age"}},
+		{`let {"first name": first, pair: [a, _]} = h;`, `let {"first name": first, pair: [a, _]} = h;`, []string{"first", "a"}},
+		{"const {} = h;", "const {} = h;", nil},
+		{"let {a: {b}} = h;", "let {a: {b}} = h;", []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+		checkProgramLength(t, 1, program)
+
+		statement := program.Statements[0].(*ast.LetStatement)
+		if _, ok := statement.Pattern.(*ast.HashPattern); !ok {
+			t.Fatalf("statement.Pattern expected : *ast.HashPattern, but was actual : %T", statement.Pattern)
+		}
+
+		var names []string
+		for _, name := range ast.PatternNames(statement.Pattern) {
+			names = append(names, name.Value)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.names, ",") {
+			t.Errorf("names of %q expected : %q, but was actual : %q", tt.input, tt.names, names)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("program expected : %s, but was actual : %s", tt.expected, program.String())
+		}
+	}
+}
+
+func TestDestructuringLetStatementErrors(t *testing.T) {
+	tests := []string{
+		"let [a, +] = b;",
+		"let [...rest, a] = b;",
+		"let [a, b = c;",
+		"let {1} = h;",
+		`let {"a"} = h;`,
+		"let {a b} = h;",
+		"let {a: } = h;",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser expected errors for %q", input)
+		}
+	}
+}
+
+func TestConstStatements(t *testing.T) {
+	input := `
+	const x = 5;
+	const [a, b] = pair;
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkProgramLength(t, 2, program)
+
+	statement, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("statement expected : ast.LetStatement, but was actual : %T", program.Statements[0])
+	}
+
+	if statement.TokenLiteral() != "const" {
+		t.Errorf("statement.TokenLiteral expected : const, but was actual : %s", statement.TokenLiteral())
+	}
+
+	testIdentifier(t, statement.Name, "x")
+
+	if program.String() != "const x = 5;const [a, b] = pair;" {
+		t.Errorf("program expected : const x = 5;const [a, b] = pair;, but was actual : %s", program.String())
+	}
+}
+
+func TestConstRedefinition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"const x = 1; let x = 2;", []string{"cannot redefine constant x"}},
+		{"const x = 1; const x = 2;", []string{"cannot redefine constant x"}},
+		{"const [x, ...y] = a; let [y] = b;", []string{"cannot redefine constant y"}},
+		{"const x = 1; if (true) { let x = 2; }", []string{"cannot redefine constant x"}},
+		{"let x = 1; const x = 2; let x = 3;", []string{"cannot redefine constant x"}},
+		{"let x = 1; let x = 2; const x = 3;", nil},
+		{"const x = 1; let f = fn(x) { let x = 2; x };", nil},
+		{"let f = fn() { const x = 1; }; let x = 2;", nil},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) != len(tt.expected) {
+			t.Errorf("errors of %q expected : %v, but was actual : %v", tt.input, tt.expected, p.Errors())
+			continue
+		}
+
+		for i, message := range tt.expected {
+			if p.Errors()[i] != message {
+				t.Errorf("error expected : %s, but was actual : %s", message, p.Errors()[i])
+			}
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	input := `
 	return 5;
@@ -289,6 +472,7 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
 		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
 		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+		{"a * b[1:][0] * c", "((a * ((b[1:])[0])) * c)"},
 	}
 
 	for _, tt := range tests {
@@ -544,6 +728,156 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"myArray[1:3]", "(myArray[1:3])"},
+		{"myArray[:-1]", "(myArray[:(-1)])"},
+		{"myString[2:]", "(myString[2:])"},
+		{"myArray[:]", "(myArray[:])"},
+		{"myArray[1 + 1:len(myArray)]", "(myArray[(1 + 1):len(myArray)])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+		checkProgramLength(t, 1, program)
+
+		statement := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := statement.Expression.(*ast.SliceExpression); !ok {
+			t.Fatalf("statement.Expression expected : ast.SliceExpression, but was actual : %T", statement.Expression)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("program expected : %s, but was actual : %s", tt.expected, program.String())
+		}
+	}
+}
+
+func TestParsingHashLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{}`, "{}"},
+		{`{"one": 1, "two": 2}`, "{one: 1, two: 2}"},
+		{`{1: true, true: "a"}`, "{1: true, true: a}"},
+		{`{"one": 0 + 1, "two": 10 - 8}`, "{one: (0 + 1), two: (10 - 8)}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+		checkProgramLength(t, 1, program)
+
+		statement := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := statement.Expression.(*ast.HashLiteral); !ok {
+			t.Fatalf("statement.Expression expected : ast.HashLiteral, but was actual : %T", statement.Expression)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("program expected : %s, but was actual : %s", tt.expected, program.String())
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (x) {
+		1 => "one",
+		-1 => "minus one",
+		[a, b] => a + b,
+		"a" | "b" => { let c = 1; c },
+		n if n > 10 => n,
+		[head, ...tail] => tail,
+		true => 1,
+		_ => 0,
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+	checkProgramLength(t, 1, program)
+
+	statement := program.Statements[0].(*ast.ExpressionStatement)
+	match, ok := statement.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("statement.Expression expected : ast.MatchExpression, but was actual : %T", statement.Expression)
+	}
+
+	if !testIdentifier(t, match.Subject, "x") {
+		return
+	}
+
+	expected := []struct {
+		pattern string
+		guard   string
+		body    string
+	}{
+		{"1", "", `one`},
+		{"(-1)", "", `minus one`},
+		{"[a, b]", "", "(a + b)"},
+		{`"a" | "b"`, "", "let c = 1;c"},
+		{"n", "(n > 10)", "n"},
+		{"[head, ...tail]", "", "tail"},
+		{"true", "", "1"},
+		{"_", "", "0"},
+	}
+
+	if len(match.Arms) != len(expected) {
+		t.Fatalf("len(match.Arms) expected : %d, but was actual : %d", len(expected), len(match.Arms))
+	}
+
+	for i, e := range expected {
+		arm := match.Arms[i]
+
+		if arm.Pattern.String() != e.pattern {
+			t.Errorf("arm[%d].Pattern expected : %s, but was actual : %s", i, e.pattern, arm.Pattern.String())
+		}
+
+		if e.guard == "" && arm.Guard != nil {
+			t.Errorf("arm[%d].Guard expected nil, but was actual : %s", i, arm.Guard.String())
+		}
+
+		if e.guard != "" && (arm.Guard == nil || arm.Guard.String() != e.guard) {
+			t.Errorf("arm[%d].Guard expected : %s, but was actual : %v", i, e.guard, arm.Guard)
+		}
+
+		if arm.Body.String() != e.body {
+			t.Errorf("arm[%d].Body expected : %s, but was actual : %s", i, e.body, arm.Body.String())
+		}
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []string{
+		"match x { _ => 1 }",
+		"match (x) { + => 1 }",
+		"match (x) { 1 2 }",
+		"match (x) { 1 => 1 2 => 2 }",
+		"match (x) { - a => 1 }",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser expected errors for %q", input)
+		}
+	}
+}
+
 func testNumberLiteral(t *testing.T, expression ast.Expression, value int64) bool {
 	numberLiteral, ok := expression.(*ast.NumberLiteral)
 
@@ -638,3 +972,200 @@ func checkProgramLength(t *testing.T, expected int, program *ast.Program) {
 		t.Fatalf("program.Statements does not contain %d statements. got=%d", expected, len(program.Statements))
 	}
 }
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x 5;", []string{"1:7 : next token expected : =, but was actual : NUMBER"}},
+		{"let x = 1;\n  let = 2;", []string{"2:7 : next token expected : ID, but was actual : ="}},
+		{"const a = 1;\nconst a = 2;", []string{"2:7 : cannot redefine constant a"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		var actual []string
+		for _, err := range p.Diagnostics() {
+			actual = append(actual, err.Error())
+		}
+
+		if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("errors of %q expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestExcerptReader(t *testing.T) {
+	input := "let a = 1;\r\n\tlet b = ;\n" + strings.Repeat("x", 10000) + " let c 3;\n" +
+		strings.Repeat("y", EXCERPT_SIZE) + " let d 4;\nlet e = 5;\nlet f 6"
+
+	readers := map[string]func(io.Reader) io.Reader{
+		"chunks":   func(r io.Reader) io.Reader { return r },
+		"one byte": iotest.OneByteReader,
+	}
+
+	for name, wrap := range readers {
+		excerpts := NewExcerptReader(wrap(strings.NewReader(input)))
+		p := New(lexer.NewReader(excerpts))
+		excerpts.Track(p)
+		p.ParseProgram()
+
+		errors := p.Diagnostics()
+		lines := excerpts.Lines()
+
+		var actual []int
+		for _, e := range errors {
+			line, ok := lines[e.Position.Line]
+			if !ok {
+				continue
+			}
+			actual = append(actual, e.Position.Line)
+
+			if description := e.DescribeLine(line); description != e.Describe(input) {
+				t.Errorf("%s : DescribeLine of %s expected : %q, but was actual : %q", name, e, e.Describe(input), description)
+			}
+		}
+
+		expected := []int{2, 3, 6}
+		if fmt.Sprint(actual) != fmt.Sprint(expected) {
+			t.Errorf("%s : excerpt lines expected : %v, but was actual : %v", name, expected, actual)
+		}
+	}
+
+	p := New(lexer.New(input))
+	p.ParseProgram()
+	if actual := p.Diagnostics()[0].DescribeLine(""); actual != p.Diagnostics()[0].Error() {
+		t.Errorf("DescribeLine of a short line expected : %q, but was actual : %q", p.Diagnostics()[0].Error(), actual)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []string{
+		"let x = 5; const y = -x * (2 + 3); return y;",
+		`let greet = fn(name, greeting) { greeting + ", " + name }; greet("monkey", "hello")`,
+		"if (x < 10) { x } else { !true == false }",
+		"if (x) { 1 }",
+		`let h = {"a": [1, 2, 3][1:], true: fn() { return 1; }, 1: arr[0]}; h["a"][:2]`,
+		"let [a, [b, _], ...rest] = [1, [2, 3], 4];",
+		`match (x) { 1 | 2 => "small", [a, ...r] if a > 0 => a, "s" => s, -1 => {}, _ => { let y = x; y } }`,
+		`let {name, "age": [a, ...r], nested: {x: 1 | 2}} = person; match (p) { {kind: "circle", r} => r }`,
+		"map([], fn(x) { x })(1)(2)",
+		"",
+	}
+
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		encoded, err := json.Marshal(program)
+		if err != nil {
+			t.Fatalf("marshal of %q returned error : %s", input, err)
+		}
+
+		var decoded ast.Program
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatalf("unmarshal of %q returned error : %s", input, err)
+		}
+
+		if decoded.String() != program.String() {
+			t.Errorf("round trip of %q expected : %q, but was actual : %q", input, program.String(), decoded.String())
+		}
+
+		reencoded, err := json.Marshal(&decoded)
+		if err != nil {
+			t.Fatalf("marshal of decoded %q returned error : %s", input, err)
+		}
+		if string(reencoded) != string(encoded) {
+			t.Errorf("re-encoding of %q expected : %s, but was actual : %s", input, encoded, reencoded)
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   []string
+		statements int
+	}{
+		{"let = 1 + 2 * (3; let y = 2; y", []string{"1:5 : next token expected : ID, but was actual : ="}, 2},
+		{"let x 1 2 3\nlet y = 2;", []string{"1:7 : next token expected : =, but was actual : NUMBER"}, 1},
+		{"let f = fn() { let = 1; 2 }; f()", []string{"1:20 : next token expected : ID, but was actual : ="}, 2},
+		{"if (x) { let = [1, 2]; let z 3; z }", []string{"1:14 : next token expected : ID, but was actual : =", "1:30 : next token expected : =, but was actual : NUMBER"}, 1},
+		{"let a = ;\nlet b = );\nreturn ];", []string{"1:9 : no prefix parse function for ;", "2:9 : no prefix parse function for )", "3:8 : no prefix parse function for ]"}, 3},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		var actual []string
+		for _, err := range p.Diagnostics() {
+			actual = append(actual, err.Error())
+		}
+
+		if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("errors of %q expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+		if len(program.Statements) != tt.statements {
+			t.Errorf("statements of %q expected : %d, but was actual : %d (%s)", tt.input, tt.statements, len(program.Statements), program.String())
+		}
+	}
+}
+
+func TestErrorTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected token.Type
+		actual   token.Type
+	}{
+		{"let x 5;", token.ASSIGN, token.NUMBER},
+		{"(1 + 2;", token.RPAREN, token.SEMICOLON},
+		{"1 + ;", "", token.SEMICOLON},
+		{"match (x) { + => 1 }", "", token.PLUS},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Diagnostics()
+		if len(errors) == 0 {
+			t.Errorf("parser expected errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0].Expected != tt.expected || errors[0].Actual != tt.actual {
+			t.Errorf("tokens of %q expected : %q/%q, but was actual : %q/%q", tt.input, tt.expected, tt.actual, errors[0].Expected, errors[0].Actual)
+		}
+	}
+}
+
+func TestErrorDescribe(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x 5;", "1:7 : next token expected : =, but was actual : NUMBER\n 1 | let x 5;\n   |       ^"},
+		{"let a = 1;\n\tlet b = ;\nb", "2:10 : no prefix parse function for ;\n 2 | \tlet b = ;\n   | \t        ^"},
+		{"let x = (1", "1:11 : next token expected : ), but was actual : EOF\n 1 | let x = (1\n   |           ^"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Diagnostics()
+		if len(errors) == 0 {
+			t.Errorf("parser expected errors for %q", tt.input)
+			continue
+		}
+
+		if actual := errors[0].Describe(tt.input); actual != tt.expected {
+			t.Errorf("description of %q expected :\n%s\nbut was actual :\n%s", tt.input, tt.expected, actual)
+		}
+	}
+}
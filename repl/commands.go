@@ -0,0 +1,183 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type command struct {
+	usage       string
+	description string
+	run         func(s *session, argument string)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		":env":    {":env", "list bindings in the session environment", (*session).env},
+		":ast":    {":ast <expr>", "print the parsed tree of an expression", (*session).ast},
+		":tokens": {":tokens <expr>", "print the tokens of an expression", (*session).tokens},
+		":type":   {":type <expr>", "print the type of an evaluated expression", (*session).typeOf},
+		":load":   {":load <file>", "evaluate a file in the session", (*session).load},
+		":save":   {":save <file>", "save the session history to a file", (*session).save},
+		":reset":  {":reset", "clear the session environment and history", (*session).reset},
+		":help":   {":help", "show this help", (*session).help},
+	}
+}
+
+var commandOrder = []string{":env", ":ast", ":tokens", ":type", ":load", ":save", ":reset", ":help"}
+
+func (s *session) command(line string) {
+	name, argument, _ := strings.Cut(line, " ")
+	argument = strings.TrimSpace(argument)
+
+	c, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(s.out, "unknown command : %s (type :help for a list of commands)\n", name)
+		return
+	}
+
+	c.run(s, argument)
+}
+
+func (s *session) env(argument string) {
+	for _, name := range s.environment.Names() {
+		value, _ := s.environment.Get(name)
+
+		keyword := "let"
+		if s.environment.IsConstant(name) {
+			keyword = "const"
+		}
+
+		fmt.Fprintf(s.out, "%s %s = %s\n", keyword, name, value.Inspect())
+	}
+}
+
+func (s *session) ast(argument string) {
+	program, ok := s.parse(argument)
+	if !ok {
+		return
+	}
+
+	for _, statement := range program.Statements {
+		fmt.Fprintf(s.out, "%s : %s\n", strings.TrimPrefix(fmt.Sprintf("%T", statement), "*ast."), statement.String())
+	}
+}
+
+func (s *session) tokens(argument string) {
+	l := lexer.New(argument)
+
+	for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
+		fmt.Fprintf(s.out, "%-10s %q\n", t.Type, t.Literal)
+	}
+}
+
+func (s *session) typeOf(argument string) {
+	program, ok := s.parse(argument)
+	if !ok {
+		return
+	}
+
+	evaluated := evaluator.Evaluate(program, s.environment)
+	if evaluated == nil {
+		evaluated = evaluator.NULL
+	}
+
+	if evaluator.IsError(evaluated) {
+		fmt.Fprintln(s.out, evaluated.Inspect())
+		return
+	}
+
+	fmt.Fprintln(s.out, evaluated.Type())
+}
+
+func (s *session) load(argument string) {
+	if argument == "" {
+		fmt.Fprintln(s.out, "usage : :load <file>")
+		return
+	}
+
+	file, err := os.Open(argument)
+	if err != nil {
+		fmt.Fprintf(s.out, "could not load %s : %s\n", argument, err)
+		return
+	}
+	defer file.Close()
+
+	var source strings.Builder
+	program, ok := s.parseFrom(lexer.NewReader(io.TeeReader(file, &source)), func(errors []parser.Error) []string {
+		descriptions := make([]string, len(errors))
+		for i, err := range errors {
+			descriptions[i] = err.Describe(source.String())
+		}
+		return descriptions
+	})
+	if !ok {
+		return
+	}
+
+	s.run(program, source.String())
+}
+
+func (s *session) save(argument string) {
+	if argument == "" {
+		fmt.Fprintln(s.out, "usage : :save <file>")
+		return
+	}
+
+	if err := s.writeHistory(argument); err != nil {
+		fmt.Fprintf(s.out, "could not save %s : %s\n", argument, err)
+		return
+	}
+
+	fmt.Fprintf(s.out, "saved %d entries to %s\n", len(s.history), argument)
+}
+
+func (s *session) writeHistory(name string) error {
+	file, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	out := bufio.NewWriter(file)
+	for _, source := range s.history {
+		out.WriteString(source)
+		out.WriteString("\n")
+	}
+
+	if err := out.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), name)
+}
+
+func (s *session) reset(argument string) {
+	s.environment = object.NewEnvironment()
+	s.history = nil
+}
+
+func (s *session) help(argument string) {
+	for _, name := range commandOrder {
+		c := commands[name]
+		fmt.Fprintf(s.out, "%-16s %s\n", c.usage, c.description)
+	}
+}
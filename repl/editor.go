@@ -0,0 +1,347 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+const maxHistory = 1000
+
+var errInterrupted = errors.New("interrupted")
+
+type editor struct {
+	in        *bufio.Reader
+	out       io.Writer
+	complete  func(word string) []string
+	highlight func(line string) string
+
+	history []string
+
+	prompt  string
+	line    []rune
+	cursor  int
+	index   int
+	pending []rune
+}
+
+func newEditor(in io.Reader, out io.Writer, complete func(word string) []string) *editor {
+	return &editor{in: bufio.NewReader(in), out: out, complete: complete}
+}
+
+func (e *editor) addHistory(line string) bool {
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return false
+	}
+
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+	return true
+}
+
+func (e *editor) readLine(prompt string) (string, error) {
+	e.prompt = prompt
+	e.line = nil
+	e.cursor = 0
+	e.index = len(e.history)
+	e.pending = nil
+
+	io.WriteString(e.out, prompt)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(e.line) > 0 {
+				io.WriteString(e.out, "\r\n")
+				return string(e.line), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyLineFeed:
+			io.WriteString(e.out, "\r\n")
+			return string(e.line), nil
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteForward()
+		case keyBackspace, keyDelete:
+			e.deleteBackward()
+		case keyTab:
+			e.completeWord()
+		case keyCtrlA:
+			e.moveTo(0)
+		case keyCtrlE:
+			e.moveTo(len(e.line))
+		case keyCtrlB:
+			e.moveTo(e.cursor - 1)
+		case keyCtrlF:
+			e.moveTo(e.cursor + 1)
+		case keyCtrlK:
+			e.line = e.line[:e.cursor]
+			e.refresh()
+		case keyCtrlU:
+			e.line = append([]rune{}, e.line[e.cursor:]...)
+			e.cursor = 0
+			e.refresh()
+		case keyCtrlW:
+			e.deleteWord()
+		case keyCtrlL:
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+			e.refresh()
+		case keyCtrlP:
+			e.previousHistory()
+		case keyCtrlN:
+			e.nextHistory()
+		case keyEscape:
+			e.escape()
+		default:
+			if unicode.IsPrint(r) {
+				e.insert([]rune{r})
+			}
+		}
+	}
+}
+
+func (e *editor) escape() {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return
+	}
+
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return
+	}
+
+	var parameter []rune
+	for '0' <= r && r <= '9' {
+		parameter = append(parameter, r)
+		if r, _, err = e.in.ReadRune(); err != nil {
+			return
+		}
+	}
+
+	switch r {
+	case 'A':
+		e.previousHistory()
+	case 'B':
+		e.nextHistory()
+	case 'C':
+		e.moveTo(e.cursor + 1)
+	case 'D':
+		e.moveTo(e.cursor - 1)
+	case 'H':
+		e.moveTo(0)
+	case 'F':
+		e.moveTo(len(e.line))
+	case '~':
+		switch string(parameter) {
+		case "1", "7":
+			e.moveTo(0)
+		case "4", "8":
+			e.moveTo(len(e.line))
+		case "3":
+			e.deleteForward()
+		}
+	}
+}
+
+func (e *editor) insert(runes []rune) {
+	line := make([]rune, 0, len(e.line)+len(runes))
+	line = append(line, e.line[:e.cursor]...)
+	line = append(line, runes...)
+	line = append(line, e.line[e.cursor:]...)
+
+	e.line = line
+	e.cursor += len(runes)
+	e.refresh()
+}
+
+func (e *editor) deleteBackward() {
+	if e.cursor == 0 {
+		return
+	}
+
+	e.line = append(e.line[:e.cursor-1], e.line[e.cursor:]...)
+	e.cursor--
+	e.refresh()
+}
+
+func (e *editor) deleteForward() {
+	if e.cursor == len(e.line) {
+		return
+	}
+
+	e.line = append(e.line[:e.cursor], e.line[e.cursor+1:]...)
+	e.refresh()
+}
+
+func (e *editor) deleteWord() {
+	start := e.cursor
+	for start > 0 && unicode.IsSpace(e.line[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(e.line[start-1]) {
+		start--
+	}
+
+	e.line = append(e.line[:start], e.line[e.cursor:]...)
+	e.cursor = start
+	e.refresh()
+}
+
+func (e *editor) moveTo(cursor int) {
+	if cursor < 0 || cursor > len(e.line) || cursor == e.cursor {
+		return
+	}
+
+	e.cursor = cursor
+	e.refresh()
+}
+
+func (e *editor) previousHistory() {
+	if e.index == 0 {
+		return
+	}
+	if e.index == len(e.history) {
+		e.pending = e.line
+	}
+
+	e.index--
+	e.setLine([]rune(e.history[e.index]))
+}
+
+func (e *editor) nextHistory() {
+	if e.index == len(e.history) {
+		return
+	}
+
+	e.index++
+	if e.index == len(e.history) {
+		e.setLine(e.pending)
+	} else {
+		e.setLine([]rune(e.history[e.index]))
+	}
+}
+
+func (e *editor) setLine(line []rune) {
+	e.line = append([]rune{}, line...)
+	e.cursor = len(e.line)
+	e.refresh()
+}
+
+func (e *editor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+
+	start := e.cursor
+	for start > 0 && isWordRune(e.line[start-1]) {
+		start--
+	}
+	if start > 0 && e.line[start-1] == ':' && strings.TrimSpace(string(e.line[:start-1])) == "" {
+		start--
+	}
+
+	word := string(e.line[start:e.cursor])
+	if word == "" {
+		return
+	}
+
+	candidates := e.complete(word)
+	if len(candidates) == 0 {
+		return
+	}
+
+	prefix := commonPrefix(candidates)
+	if len(prefix) > len(word) {
+		e.insert([]rune(prefix[len(word):]))
+		return
+	}
+	if len(candidates) == 1 {
+		return
+	}
+
+	io.WriteString(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+	e.refresh()
+}
+
+func (e *editor) refresh() {
+	line := string(e.line)
+	if e.highlight != nil {
+		line = e.highlight(line)
+	}
+
+	io.WriteString(e.out, "\r"+e.prompt+line+"\x1b[K")
+	if back := len(e.line) - e.cursor; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+func completions(word string, sources ...[]string) []string {
+	seen := make(map[string]bool)
+	var candidates []string
+
+	for _, source := range sources {
+		for _, name := range source {
+			if strings.HasPrefix(name, word) && !seen[name] {
+				seen[name] = true
+				candidates = append(candidates, name)
+			}
+		}
+	}
+
+	sort.Strings(candidates)
+	return candidates
+}
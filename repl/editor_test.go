@@ -0,0 +1,116 @@
+package repl
+
+import (
+	"io"
+	"monkey/object"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditorReadLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		history  []string
+		expected string
+	}{
+		{"let x = 1;\r", nil, "let x = 1;"},
+		{"1 + 2\n", nil, "1 + 2"},
+		{"ac\x1b[Db\r", nil, "abc"},
+		{"bc\x01a\x05d\r", nil, "abcd"},
+		{"abcx\x7f\r", nil, "abc"},
+		{"abxc\x1b[D\x1b[D\x1b[3~\r", nil, "abc"},
+		{"let x = 1\x17y\r", nil, "let x = y"},
+		{"abc\x1b[D\x0b\r", nil, "ab"},
+		{"abc\x1b[D\x15\r", nil, "c"},
+		{"\x1b[A\r", []string{"first", "second"}, "second"},
+		{"\x1b[A\x1b[A\r", []string{"first", "second"}, "first"},
+		{"\x1b[A\x1b[A\x1b[A\r", []string{"first", "second"}, "first"},
+		{"new\x1b[A\x1b[B\r", []string{"first"}, "new"},
+		{"let x = fa\t || pu\t\r", nil, "let x = false || push"},
+		{"f\t\r", nil, "f"},
+		{"ret\tabc\t\r", nil, "returnabc"},
+	}
+
+	for _, tt := range tests {
+		e := newEditor(strings.NewReader(tt.input), io.Discard, func(word string) []string {
+			return completions(word, []string{"let", "return", "fn", "false"}, []string{"push", "len"})
+		})
+		for _, line := range tt.history {
+			e.addHistory(line)
+		}
+
+		actual, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("readLine(%q) returned error : %s", tt.input, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("readLine(%q) expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestEditorControlKeys(t *testing.T) {
+	e := newEditor(strings.NewReader("abc\x03\x04"), io.Discard, nil)
+
+	if _, err := e.readLine(PROMPT); err != errInterrupted {
+		t.Errorf("ctrl-c expected : %v, but was actual : %v", errInterrupted, err)
+	}
+	if _, err := e.readLine(PROMPT); err != io.EOF {
+		t.Errorf("ctrl-d expected : %v, but was actual : %v", io.EOF, err)
+	}
+}
+
+func TestEditorHistory(t *testing.T) {
+	e := newEditor(strings.NewReader(""), io.Discard, nil)
+
+	for _, line := range []string{"a", "a", "", "  ", "b", "a"} {
+		e.addHistory(line)
+	}
+
+	expected := []string{"a", "b", "a"}
+	if strings.Join(e.history, ",") != strings.Join(expected, ",") {
+		t.Errorf("history expected : %q, but was actual : %q", expected, e.history)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := &session{environment: object.NewEnvironment()}
+	s.environment.Set("counter", &object.Integer{Value: 1})
+
+	tests := []struct {
+		word     string
+		expected []string
+	}{
+		{"co", []string{"const", "counter"}},
+		{"le", []string{"len", "let"}},
+		{"ma", []string{"map", "match"}},
+		{":re", []string{":reset"}},
+		{"zz", nil},
+	}
+
+	for _, tt := range tests {
+		actual := s.complete(tt.word)
+		if strings.Join(actual, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("complete(%q) expected : %q, but was actual : %q", tt.word, tt.expected, actual)
+		}
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	appendHistory(path, "let x = 1;")
+	appendHistory(path, "x + 1")
+
+	actual := loadHistory(path)
+	expected := []string{"let x = 1;", "x + 1"}
+	if strings.Join(actual, ",") != strings.Join(expected, ",") {
+		t.Errorf("history expected : %q, but was actual : %q", expected, actual)
+	}
+
+	if actual := loadHistory(filepath.Join(t.TempDir(), "missing")); actual != nil {
+		t.Errorf("missing history expected : nil, but was actual : %q", actual)
+	}
+}
@@ -0,0 +1,80 @@
+package repl
+
+import (
+	"monkey/lexer"
+	"monkey/token"
+	"strings"
+)
+
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+)
+
+func paint(color string, s string) string {
+	if color == "" || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func tokenColor(t token.Token) string {
+	switch t.Type {
+	case token.NUMBER:
+		return colorCyan
+	case token.STRING:
+		return colorGreen
+	case token.TRUE, token.FALSE:
+		return colorYellow
+	case token.ILLEGAL:
+		return colorRed
+	case token.ID:
+		return ""
+	}
+
+	if token.FindStringType(t.Literal) == t.Type {
+		return colorMagenta
+	}
+	return ""
+}
+
+func highlight(source string) string {
+	var out strings.Builder
+
+	l := lexer.New(source)
+	offset := 0
+
+	for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
+		start := offset
+		for start < len(source) && strings.IndexByte(" \t\n\r", source[start]) >= 0 {
+			start++
+		}
+
+		end := start + tokenLength(t)
+		if end > len(source) {
+			end = len(source)
+		}
+
+		out.WriteString(source[offset:start])
+		out.WriteString(paint(tokenColor(t), source[start:end]))
+		offset = end
+	}
+
+	out.WriteString(source[offset:])
+	return out.String()
+}
+
+func tokenLength(t token.Token) int {
+	switch {
+	case t.Type == token.STRING:
+		return len(t.Literal) + 2
+	case t.Type == token.ILLEGAL && !strings.HasPrefix(t.Literal, `"`):
+		return 1
+	default:
+		return len(t.Literal)
+	}
+}
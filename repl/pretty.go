@@ -0,0 +1,103 @@
+package repl
+
+import (
+	"monkey/object"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const indentation = "  "
+
+type printer struct {
+	color bool
+}
+
+func (p printer) paint(color string, s string) string {
+	if !p.color {
+		return s
+	}
+	return paint(color, s)
+}
+
+func (p printer) repr(o object.Object) string {
+	var out strings.Builder
+	p.write(&out, o, "")
+	return out.String()
+}
+
+func (p printer) write(out *strings.Builder, o object.Object, indent string) {
+	switch o := o.(type) {
+	case *object.String:
+		out.WriteString(p.paint(colorGreen, strconv.Quote(o.Value)))
+	case *object.Integer:
+		out.WriteString(p.paint(colorCyan, o.Inspect()))
+	case *object.Boolean, *object.Null:
+		out.WriteString(p.paint(colorYellow, o.Inspect()))
+	case *object.Error, *object.LimitError:
+		out.WriteString(p.paint(colorRed, o.Inspect()))
+	case *object.Array:
+		p.writeCollection(out, "[", "]", o.Elements(), nil, indent)
+	case *object.Hash:
+		pairs := make([]object.HashPair, 0, len(o.Pairs))
+		for _, pair := range o.Pairs {
+			pairs = append(pairs, pair)
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return printer{}.repr(pairs[i].Key) < printer{}.repr(pairs[j].Key)
+		})
+
+		keys := make([]object.Object, len(pairs))
+		values := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			keys[i] = pair.Key
+			values[i] = pair.Value
+		}
+		p.writeCollection(out, "{", "}", values, keys, indent)
+	default:
+		out.WriteString(o.Inspect())
+	}
+}
+
+func (p printer) writeCollection(out *strings.Builder, open string, close string, values []object.Object, keys []object.Object, indent string) {
+	multiline := false
+	for _, value := range values {
+		if isNested(value) {
+			multiline = true
+			break
+		}
+	}
+
+	out.WriteString(open)
+	for i, value := range values {
+		if multiline {
+			out.WriteString("\n" + indent + indentation)
+		} else if i > 0 {
+			out.WriteString(" ")
+		}
+
+		if keys != nil {
+			p.write(out, keys[i], indent+indentation)
+			out.WriteString(": ")
+		}
+		p.write(out, value, indent+indentation)
+
+		if i < len(values)-1 {
+			out.WriteString(",")
+		}
+	}
+	if multiline {
+		out.WriteString("\n" + indent)
+	}
+	out.WriteString(close)
+}
+
+func isNested(o object.Object) bool {
+	switch o := o.(type) {
+	case *object.Array:
+		return o.Len() > 0
+	case *object.Hash:
+		return len(o.Pairs) > 0
+	}
+	return false
+}
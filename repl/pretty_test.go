@@ -0,0 +1,68 @@
+package repl
+
+import (
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+)
+
+func TestRepr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1`, `1`},
+		{`"1"`, `"1"`},
+		{"\"a\nb\"", `"a\nb"`},
+		{`true`, `true`},
+		{`[]`, `[]`},
+		{`[1, "two", true]`, `[1, "two", true]`},
+		{`[[1, 2], [], "a"]`, "[\n  [1, 2],\n  [],\n  \"a\"\n]"},
+		{`[[1, [2]]]`, "[\n  [\n    1,\n    [2]\n  ]\n]"},
+		{`{"b": 2, "a": "x"}`, `{"a": "x", "b": 2}`},
+		{`{"a": [1]}`, "{\n  \"a\": [1]\n}"},
+		{`1 + true`, `ERROR :type mismatch : INTEGER + BOOLEAN`},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		evaluated := evaluator.Evaluate(program, object.NewEnvironment())
+
+		if actual := (printer{}).repr(evaluated); actual != tt.expected {
+			t.Errorf("repr of %s expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestReprColor(t *testing.T) {
+	array := object.NewArray([]object.Object{&object.Integer{Value: 1}, &object.String{Value: "a"}})
+
+	expected := "[" + colorCyan + "1" + colorReset + ", " + colorGreen + `"a"` + colorReset + "]"
+	if actual := (printer{color: true}).repr(array); actual != expected {
+		t.Errorf("repr expected : %q, but was actual : %q", expected, actual)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"x", "x"},
+		{"let x = 5;", colorMagenta + "let" + colorReset + " x = " + colorCyan + "5" + colorReset + ";"},
+		{`  "a b" + true`, "  " + colorGreen + `"a b"` + colorReset + " + " + colorYellow + "true" + colorReset},
+		{"fn(x) { x }", colorMagenta + "fn" + colorReset + "(x) { x }"},
+		{"a . b", "a " + colorRed + "." + colorReset + " b"},
+		{`f("abc`, "f(" + colorRed + `"abc` + colorReset},
+		{"x ", "x "},
+	}
+
+	for _, tt := range tests {
+		if actual := highlight(tt.input); actual != tt.expected {
+			t.Errorf("highlight(%q) expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+	}
+}
@@ -1,50 +1,191 @@
 package repl
 
 import (
-	"bufio"
-	"fmt"
 	"io"
+	"monkey/ast"
 	"monkey/evaluator"
 	"monkey/lexer"
 	"monkey/object"
+	"monkey/optimizer"
 	"monkey/parser"
+	"monkey/token"
+	"strings"
 )
 
-const PROMPT = ">> "
+const (
+	PROMPT              = ">> "
+	CONTINUATION_PROMPT = ".. "
+)
+
+type Options struct {
+	NoColor  bool
+	Optimize bool
+	DumpAST  bool
+}
+
+type session struct {
+	out         io.Writer
+	environment *object.Environment
+	history     []string
+	pretty      bool
+	printer     printer
+	optimize    bool
+	dumpAST     bool
+}
 
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	environment := object.NewEnvironment()
+	StartWithOptions(in, out, Options{})
+}
+
+func StartWithOptions(in io.Reader, out io.Writer, options Options) {
+	terminal := isTerminalWriter(out)
+	color := terminal && !options.NoColor
+
+	s := &session{
+		out:         out,
+		environment: object.NewEnvironment(),
+		pretty:      terminal,
+		printer:     printer{color: color},
+		optimize:    options.Optimize,
+		dumpAST:     options.DumpAST,
+	}
+
+	var colorize func(line string) string
+	if color {
+		colorize = highlight
+	}
+	reader := newLineReader(in, out, s.complete, colorize)
+
+	var lines []string
 
 	for {
-		fmt.Fprintf(out, PROMPT)
-		input := scanner.Scan()
+		prompt := PROMPT
+		if len(lines) > 0 {
+			prompt = CONTINUATION_PROMPT
+		}
 
-		if !input {
+		line, err := reader.ReadLine(prompt)
+		if err == errInterrupted {
+			lines = nil
+			continue
+		}
+		if err != nil {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
-		program := p.ParseProgram()
+		if len(lines) == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			s.command(strings.TrimSpace(line))
+			continue
+		}
+
+		lines = append(lines, line)
+		source := strings.Join(lines, "\n")
 
-		if len(p.Errors()) != 0 {
-			printParseErrors(out, p.Errors())
+		if !isComplete(source) {
 			continue
 		}
+		lines = nil
 
-		evaluated := evaluator.Evaluate(program, environment)
+		s.evaluate(source)
+	}
+}
 
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+func (s *session) complete(word string) []string {
+	if strings.HasPrefix(word, ":") {
+		return completions(word, commandOrder)
+	}
+
+	return completions(word, token.Keywords(), evaluator.NewBuiltins().Names(), s.environment.Names())
+}
+
+func (s *session) evaluate(source string) {
+	program, ok := s.parse(source)
+	if !ok {
+		return
+	}
+
+	s.run(program, source)
+}
+
+func (s *session) run(program *ast.Program, source string) {
+	if s.optimize {
+		program = optimizer.Optimize(program)
+	}
+	if s.dumpAST {
+		io.WriteString(s.out, program.String())
+		io.WriteString(s.out, "\n")
+	}
+
+	evaluated := evaluator.Evaluate(program, s.environment)
+
+	if evaluated != nil {
+		io.WriteString(s.out, s.format(evaluated))
+		io.WriteString(s.out, "\n")
+	}
+
+	if !evaluator.IsError(evaluated) {
+		s.history = append(s.history, source)
+	}
+}
+
+func (s *session) format(o object.Object) string {
+	if !s.pretty {
+		return o.Inspect()
+	}
+	return s.printer.repr(o)
+}
+
+func (s *session) parse(source string) (*ast.Program, bool) {
+	return s.parseFrom(lexer.New(source), func(errors []parser.Error) []string {
+		descriptions := make([]string, len(errors))
+		for i, err := range errors {
+			descriptions[i] = err.Describe(source)
 		}
+		return descriptions
+	})
+}
+
+func (s *session) parseFrom(l *lexer.Lexer, describe func(errors []parser.Error) []string) (*ast.Program, bool) {
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if err := l.Err(); err != nil {
+		io.WriteString(s.out, "could not read input : "+err.Error()+"\n")
+		return nil, false
 	}
+
+	if len(p.Errors()) != 0 {
+		printParseErrors(s.out, describe(p.Diagnostics()))
+		return nil, false
+	}
+
+	return program, true
 }
 
-func printParseErrors(out io.Writer, errors []string) {
-	for _, message := range errors {
-		io.WriteString(out, "\t"+message+"\n")
+func isComplete(source string) bool {
+	l := lexer.New(source)
+	depth := 0
+
+	for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
+		switch t.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		case token.ILLEGAL:
+			if strings.HasPrefix(t.Literal, `"`) {
+				return false
+			}
+		}
+	}
+
+	return depth <= 0
+}
+
+func printParseErrors(out io.Writer, descriptions []string) {
+	for _, description := range descriptions {
+		for _, line := range strings.Split(description, "\n") {
+			io.WriteString(out, "\t"+line+"\n")
+		}
 	}
 }
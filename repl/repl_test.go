@@ -0,0 +1,226 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsComplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 + 2", true},
+		{"let f = fn(x) {", false},
+		{"let f = fn(x) {\n x + 1\n}", true},
+		{"[1, 2,", false},
+		{"add(1,\n 2)", true},
+		{"add(1", false},
+		{`"hello`, false},
+		{"\"hello\nworld\"", true},
+		{"}", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if actual := isComplete(tt.input); actual != tt.expected {
+			t.Errorf("isComplete(%q) expected : %t, but was actual : %t", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestStartMultiLineInput(t *testing.T) {
+	input := strings.Join([]string{
+		"let add = fn(x, y) {",
+		"  x + y",
+		"};",
+		"add(1,",
+		"2)",
+		`"multi`,
+		`line"`,
+	}, "\n")
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	expected := ">> .. .. >> .. 3\n>> .. multi\nline\n>> "
+	if out.String() != expected {
+		t.Errorf("output expected : %q, but was actual : %q", expected, out.String())
+	}
+}
+
+func TestStartOptimized(t *testing.T) {
+	input := "const day = 60 * 60 * 24; if (day > 0) { day * 7 } else { 0 }"
+
+	var out bytes.Buffer
+	StartWithOptions(strings.NewReader(input), &out, Options{Optimize: true, DumpAST: true})
+
+	expected := ">> const day = 86400;604800\n604800\n>> "
+	if out.String() != expected {
+		t.Errorf("output expected : %q, but was actual : %q", expected, out.String())
+	}
+}
+
+func TestStartParseErrors(t *testing.T) {
+	input := "let x 1 2; let = 3; x"
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	expected := ">> \t1:7 : next token expected : =, but was actual : NUMBER\n" +
+		"\t 1 | let x 1 2; let = 3; x\n" +
+		"\t   |       ^\n" +
+		"\t1:16 : next token expected : ID, but was actual : =\n" +
+		"\t 1 | let x 1 2; let = 3; x\n" +
+		"\t   |                ^\n" +
+		">> "
+	if out.String() != expected {
+		t.Errorf("output expected : %q, but was actual : %q", expected, out.String())
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1;\nconst y = \"a\";\n:env", ">> >> >> let x = 1\nconst y = a\n>> "},
+		{":ast 1 + 2 * 3", ">> ExpressionStatement : (1 + (2 * 3))\n>> "},
+		{":tokens let x", ">> LET        \"let\"\nID         \"x\"\n>> "},
+		{":type \"a\"", ">> STRING\n>> "},
+		{":type [1, 2][5]", ">> NULL\n>> "},
+		{":type 1 + true", ">> ERROR :type mismatch : INTEGER + BOOLEAN\n>> "},
+		{"let loop = fn() { 1 + loop() };\n:type loop()", ">> >> LIMIT ERROR :call depth limit exceeded : 10000\n>> "},
+		{"let x = 1;\n:reset\n:env\nx", ">> >> >> >> ERROR :1:1 : identifier not found : x\n>> "},
+		{":unknown", ">> unknown command : :unknown (type :help for a list of commands)\n>> "},
+		{":load", ">> usage : :load <file>\n>> "},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(tt.input), &out)
+
+		if out.String() != tt.expected {
+			t.Errorf("output of %q expected : %q, but was actual : %q", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestLoadParseErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "broken.mk")
+	if err := os.WriteFile(file, []byte("let x = 1;\nlet y 2;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	Start(strings.NewReader(":load "+file+"\nx"), &out)
+
+	expected := ">> \t2:7 : next token expected : =, but was actual : NUMBER\n" +
+		"\t 2 | let y 2;\n" +
+		"\t   |       ^\n" +
+		">> ERROR :1:1 : identifier not found : x\n>> "
+	if out.String() != expected {
+		t.Errorf("output expected : %q, but was actual : %q", expected, out.String())
+	}
+}
+
+func TestSaveAndLoadCommands(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.mk")
+	resaved := filepath.Join(t.TempDir(), "resaved.mk")
+
+	input := strings.Join([]string{
+		"let add = fn(x, y) {",
+		"  x + y",
+		"};",
+		"1 + true",
+		"let loop = fn() { 1 + loop() }; loop()",
+		"let three = add(1, 2);",
+		":save " + file,
+		":reset",
+		":load " + file,
+		"three",
+		"let four = 4;",
+		":save " + resaved,
+	}, "\n")
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	expected := ">> .. .. >> ERROR :type mismatch : INTEGER + BOOLEAN\n>> LIMIT ERROR :call depth limit exceeded : 10000\n>> >> saved 2 entries to " + file + "\n>> >> >> 3\n>> >> saved 3 entries to " + resaved + "\n>> "
+	if out.String() != expected {
+		t.Errorf("output expected : %q, but was actual : %q", expected, out.String())
+	}
+
+	saved, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("could not read saved session : %s", err)
+	}
+
+	expectedSaved := "let add = fn(x, y) {\n  x + y\n};\nlet three = add(1, 2);\n"
+	if string(saved) != expectedSaved {
+		t.Errorf("saved session expected : %q, but was actual : %q", expectedSaved, string(saved))
+	}
+
+	resavedSession, err := os.ReadFile(resaved)
+	if err != nil {
+		t.Fatalf("could not read resaved session : %s", err)
+	}
+
+	expectedResaved := expectedSaved + "\nthree\nlet four = 4;\n"
+	if string(resavedSession) != expectedResaved {
+		t.Errorf("resaved session expected : %q, but was actual : %q", expectedResaved, string(resavedSession))
+	}
+}
+
+type editingReader struct {
+	edit   func()
+	reader io.Reader
+}
+
+func (r *editingReader) Read(p []byte) (int, error) {
+	if r.edit != nil {
+		r.edit()
+		r.edit = nil
+	}
+	return r.reader.Read(p)
+}
+
+func TestSaveLoadedSession(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.mk")
+	if err := os.WriteFile(file, []byte("let x = 1;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	edit := func() {
+		if err := os.WriteFile(file, []byte("let x = 2;\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	input := io.MultiReader(
+		strings.NewReader(":load "+file+"\n"),
+		&editingReader{edit: edit, reader: strings.NewReader("let y = x + 1;\n:save " + file)},
+	)
+
+	var out bytes.Buffer
+	Start(input, &out)
+
+	expected := ">> >> >> saved 2 entries to " + file + "\n>> "
+	if out.String() != expected {
+		t.Errorf("output expected : %q, but was actual : %q", expected, out.String())
+	}
+
+	saved, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("could not read saved session : %s", err)
+	}
+
+	expectedSaved := "let x = 1;\n\nlet y = x + 1;\n"
+	if string(saved) != expectedSaved {
+		t.Errorf("saved session expected : %q, but was actual : %q", expectedSaved, string(saved))
+	}
+}
@@ -0,0 +1,118 @@
+package repl
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const HISTORY_FILE = ".monkey_history"
+
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scannerReader) ReadLine(prompt string) (string, error) {
+	io.WriteString(r.out, prompt)
+
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	return r.scanner.Text(), nil
+}
+
+type terminalReader struct {
+	editor      *editor
+	fd          uintptr
+	historyFile string
+}
+
+func newLineReader(in io.Reader, out io.Writer, complete func(word string) []string, highlight func(line string) string) lineReader {
+	file, ok := in.(*os.File)
+	if !ok || !isTerminal(file.Fd()) {
+		return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+	}
+
+	r := &terminalReader{
+		editor:      newEditor(file, out, complete),
+		fd:          file.Fd(),
+		historyFile: historyPath(),
+	}
+	r.editor.highlight = highlight
+	for _, line := range loadHistory(r.historyFile) {
+		r.editor.addHistory(line)
+	}
+
+	return r
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+
+	line, err := r.editor.readLine(prompt)
+	restore()
+
+	if err == nil && r.editor.addHistory(line) {
+		appendHistory(r.historyFile, line)
+	}
+
+	return line, err
+}
+
+func isTerminalWriter(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	return ok && isTerminal(file.Fd())
+}
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HISTORY_FILE)
+}
+
+func loadHistory(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+	}
+	return lines
+}
+
+func appendHistory(path string, line string) {
+	if path == "" {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	io.WriteString(file, line+"\n")
+}
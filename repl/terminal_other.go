@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd
+
+package repl
+
+import "errors"
+
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("line editing is not supported on this platform")
+}
@@ -0,0 +1,185 @@
+package resolver
+
+import (
+	"monkey/ast"
+	"monkey/token"
+)
+
+type Error struct {
+	Position token.Position
+	Name     string
+	Message  string
+	Warning  bool
+}
+
+func (e Error) Error() string {
+	return e.Position.String() + " : " + e.Message
+}
+
+type scope struct {
+	names    map[string]int
+	outer    *scope
+	global   bool
+	function bool
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{names: make(map[string]int), outer: outer, function: outer != nil && outer.function}
+}
+
+func (s *scope) declare(identifier *ast.Identifier) {
+	if s.global {
+		s.names[identifier.Value] = -1
+		identifier.Binding = nil
+		return
+	}
+
+	slot, ok := s.names[identifier.Value]
+	if !ok {
+		slot = len(s.names)
+		s.names[identifier.Value] = slot
+	}
+	identifier.Binding = &ast.Binding{Depth: 0, Slot: slot}
+}
+
+type resolver struct {
+	defined  func(name string) bool
+	deferred []func()
+	errors   []Error
+}
+
+func Resolve(program *ast.Program, defined func(name string) bool) []Error {
+	r := &resolver{defined: defined}
+
+	global := newScope(nil)
+	global.global = true
+
+	for _, statement := range program.Statements {
+		r.statement(statement, global)
+	}
+
+	for len(r.deferred) > 0 {
+		next := r.deferred[0]
+		r.deferred = r.deferred[1:]
+		next()
+	}
+
+	return r.errors
+}
+
+func (r *resolver) resolve(identifier *ast.Identifier, s *scope) {
+	depth := 0
+	for current := s; current != nil; current = current.outer {
+		if slot, ok := current.names[identifier.Value]; ok {
+			if current.global {
+				identifier.Binding = nil
+			} else {
+				identifier.Binding = &ast.Binding{Depth: depth, Slot: slot}
+			}
+			return
+		}
+		depth++
+	}
+
+	identifier.Binding = nil
+	if r.defined == nil || !r.defined(identifier.Value) {
+		r.errors = append(r.errors, Error{
+			Position: identifier.Token.Position,
+			Name:     identifier.Value,
+			Message:  "identifier not found : " + identifier.Value,
+			Warning:  s.function,
+		})
+	}
+}
+
+func (r *resolver) statement(statement ast.Statement, s *scope) {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		r.expression(statement.Value, s)
+
+		if statement.Pattern != nil {
+			r.pattern(statement.Pattern, s)
+		} else if statement.Name != nil {
+			s.declare(statement.Name)
+		}
+	case *ast.ReturnStatement:
+		r.expression(statement.ReturnValue, s)
+	case *ast.ExpressionStatement:
+		r.expression(statement.Expression, s)
+	case *ast.BlockStatement:
+		r.block(statement, s)
+	}
+}
+
+func (r *resolver) block(block *ast.BlockStatement, s *scope) {
+	if block == nil {
+		return
+	}
+
+	for _, statement := range block.Statements {
+		r.statement(statement, s)
+	}
+}
+
+func (r *resolver) pattern(pattern ast.Pattern, s *scope) {
+	for _, name := range ast.PatternNames(pattern) {
+		s.declare(name)
+	}
+}
+
+func (r *resolver) expression(expression ast.Expression, s *scope) {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		r.resolve(expression, s)
+	case *ast.PrefixExpression:
+		r.expression(expression.Right, s)
+	case *ast.InfixExpression:
+		r.expression(expression.Left, s)
+		r.expression(expression.Right, s)
+	case *ast.IfExpression:
+		r.expression(expression.Condition, s)
+		r.block(expression.Consequence, s)
+		r.block(expression.Alternative, s)
+	case *ast.FunctionLiteral:
+		function := newScope(s)
+		function.function = true
+		for _, parameter := range expression.Parameters {
+			function.declare(parameter)
+		}
+		markTailCalls(expression.Body)
+		r.deferred = append(r.deferred, func() {
+			r.block(expression.Body, function)
+			expression.Slots = len(function.names)
+		})
+	case *ast.CallExpression:
+		r.expression(expression.Function, s)
+		for _, argument := range expression.Arguments {
+			r.expression(argument, s)
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range expression.Elements {
+			r.expression(element, s)
+		}
+	case *ast.IndexExpression:
+		r.expression(expression.Left, s)
+		r.expression(expression.Index, s)
+	case *ast.SliceExpression:
+		r.expression(expression.Left, s)
+		r.expression(expression.Start, s)
+		r.expression(expression.End, s)
+	case *ast.HashLiteral:
+		for _, pair := range expression.Pairs {
+			r.expression(pair.Key, s)
+			r.expression(pair.Value, s)
+		}
+	case *ast.MatchExpression:
+		r.expression(expression.Subject, s)
+		for _, arm := range expression.Arms {
+			enclosed := newScope(s)
+			r.pattern(arm.Pattern, enclosed)
+			r.expression(arm.Guard, enclosed)
+			r.block(arm.Body, enclosed)
+			arm.Slots = len(enclosed.names)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package resolver
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	input := `let g = 1;
+let f = fn(a, b) {
+  let c = a + g;
+  let inner = fn(d) { d + c + b + g };
+  match (c) { [e, ...rest] => e + a + len(rest), _ => c }
+};`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	errors := Resolve(program, func(name string) bool { return name == "len" })
+	if len(errors) != 0 {
+		t.Fatalf("unexpected errors : %v", errors)
+	}
+
+	var actual []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		if identifier, ok := node.(*ast.Identifier); ok {
+			actual = append(actual, describe(identifier))
+		}
+		return true
+	})
+
+	expected := []string{
+		"g", "f",
+		"a@0:0", "b@0:1",
+		"c@0:2", "a@0:0", "g",
+		"inner@0:3", "d@0:0", "d@0:0", "c@1:2", "b@1:1", "g",
+		"c@0:2", "e@0:0", "rest@0:1", "e@0:0", "a@1:0", "len", "rest@0:1", "_", "c@1:2",
+	}
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("bindings expected :\n%s\nbut was actual :\n%s", strings.Join(expected, " "), strings.Join(actual, " "))
+	}
+
+	function := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if function.Slots != 4 {
+		t.Errorf("function slots expected : 4, but was actual : %d", function.Slots)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"x", []string{"1:1 : identifier not found : x"}},
+		{"x; let x = 1; x", []string{"1:1 : identifier not found : x"}},
+		{"let f = fn() { later }; let later = 1;", nil},
+		{"let f = fn(a) { a + b };\nlen(c)", []string{"2:5 : identifier not found : c", "1:21 : identifier not found : b (warning)"}},
+		{"let f = fn() { match (1) { x => x + y } }", []string{"1:37 : identifier not found : y (warning)"}},
+		{"match (1) { x => y }", []string{"1:18 : identifier not found : y"}},
+		{"match (1) { x => x }; x", []string{"1:23 : identifier not found : x"}},
+		{"defined", nil},
+		{"let x = 10; let f = fn() { let g = fn() { x }; let r = g(); let x = 2; r }; f()", nil},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		errors := Resolve(program, func(name string) bool { return name == "len" || name == "defined" })
+
+		var actual []string
+		for _, err := range errors {
+			if err.Warning {
+				actual = append(actual, err.Error()+" (warning)")
+			} else {
+				actual = append(actual, err.Error())
+			}
+		}
+		if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("errors of %q expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let f = fn(n) { f(n) }", []string{"f(n)"}},
+		{"let f = fn(n) { 1 + f(n) }", nil},
+		{"let f = fn(n) { let x = f(n); x }", nil},
+		{"let f = fn(n) { if (n) { return f(n); } g(f(n)) }", []string{"f(n)", "g(f(n))"}},
+		{"let f = fn(n) { if (n) { f(n) } else { g(n) } }", []string{"f(n)", "g(n)"}},
+		{"let f = fn(n) { match (n) { 1 => f(n), _ => [g(n)] } }", []string{"f(n)"}},
+		{"let f = fn(n) { let h = fn() { g(n) }; 1 + h() }", []string{"g(n)"}},
+		{"f(1)", nil},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		Resolve(program, func(string) bool { return true })
+
+		var actual []string
+		ast.Inspect(program, func(node ast.Node) bool {
+			if call, ok := node.(*ast.CallExpression); ok && call.Tail {
+				actual = append(actual, call.String())
+			}
+			return true
+		})
+		if strings.Join(actual, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("tail calls of %q expected : %q, but was actual : %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func describe(identifier *ast.Identifier) string {
+	if identifier.Binding == nil {
+		return identifier.Value
+	}
+	return fmt.Sprintf("%s@%d:%d", identifier.Value, identifier.Binding.Depth, identifier.Binding.Slot)
+}
@@ -0,0 +1,48 @@
+package resolver
+
+import "monkey/ast"
+
+func markTailCalls(body *ast.BlockStatement) {
+	if body == nil {
+		return
+	}
+
+	tailBlock(body)
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FunctionLiteral:
+			return false
+		case *ast.ReturnStatement:
+			tailExpression(node.ReturnValue)
+		}
+		return true
+	})
+}
+
+func tailBlock(block *ast.BlockStatement) {
+	if block == nil || len(block.Statements) == 0 {
+		return
+	}
+
+	switch last := block.Statements[len(block.Statements)-1].(type) {
+	case *ast.ExpressionStatement:
+		tailExpression(last.Expression)
+	case *ast.ReturnStatement:
+		tailExpression(last.ReturnValue)
+	}
+}
+
+func tailExpression(expression ast.Expression) {
+	switch expression := expression.(type) {
+	case *ast.CallExpression:
+		expression.Tail = true
+	case *ast.IfExpression:
+		tailBlock(expression.Consequence)
+		tailBlock(expression.Alternative)
+	case *ast.MatchExpression:
+		for _, arm := range expression.Arms {
+			tailBlock(arm.Body)
+		}
+	}
+}
@@ -1,10 +1,26 @@
 package token
 
+import (
+	"fmt"
+	"sort"
+)
+
 type Type string
 
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 type Token struct {
-	Type    Type
-	Literal string
+	Type     Type     `json:"type"`
+	Literal  string   `json:"literal"`
+	Position Position `json:"position"`
 }
 
 const (
@@ -21,6 +37,10 @@ const (
 	LESS      = "<"
 	GREATER   = ">"
 	COMMA     = ","
+	COLON     = ":"
+	ELLIPSIS  = "..."
+	ARROW     = "=>"
+	PIPE      = "|"
 	SEMICOLON = ";"
 	LPAREN    = "("
 	RPAREN    = ")"
@@ -32,28 +52,45 @@ const (
 	NOT_EQUAL = "!="
 	FUNCTION  = "FUNCTION"
 	LET       = "LET"
+	CONST     = "CONST"
 	RETURN    = "RETURN"
 	TRUE      = "TRUE"
 	FALSE     = "FALSE"
 	IF        = "IF"
 	ELSE      = "ELSE"
+	MATCH     = "MATCH"
 	STRING    = "STRING"
 )
 
 var keywords = map[string]Type{
 	"fn":     FUNCTION,
 	"let":    LET,
+	"const":  CONST,
 	"return": RETURN,
 	"true":   TRUE,
 	"false":  FALSE,
 	"if":     IF,
 	"else":   ELSE,
+	"match":  MATCH,
 }
 
 func New(tokenType Type, literal string) Token {
 	return Token{Type: tokenType, Literal: literal}
 }
 
+func At(tokenType Type, literal string, position Position) Token {
+	return Token{Type: tokenType, Literal: literal, Position: position}
+}
+
+func Keywords() []string {
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func FindStringType(s string) Type {
 	if t, ok := keywords[s]; ok {
 		return t